@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// AccessRule mówi, kto może użyć komendy: wystarczy jedna z ról Roles albo
+// komplet uprawnień Permissions. Reguła bez ról i uprawnień jest otwarta.
+// Administratorzy serwera mają dostęp zawsze, żeby nie dało się zamknąć
+// sobie drogi do !uprawnienia.
+type AccessRule struct {
+	// Everyone otwiera komendę dla wszystkich, także gdy domyślnie jest zamknięta.
+	Everyone    bool     `json:"everyone,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions int64    `json:"permissions,omitempty"`
+}
+
+func (r AccessRule) open() bool {
+	return r.Everyone || (len(r.Roles) == 0 && r.Permissions == 0)
+}
+
+func (r AccessRule) allows(roles []string, perms int64) bool {
+	if r.open() || perms&discordgo.PermissionAdministrator != 0 {
+		return true
+	}
+	for _, role := range roles {
+		if slices.Contains(r.Roles, role) {
+			return true
+		}
+	}
+	return r.Permissions != 0 && perms&r.Permissions == r.Permissions
+}
+
+// accessCommands to komendy, którym da się ustawić dostęp, w kolejności
+// z !uprawnienia: cały rejestr commands i buttonAccess.
+var accessCommands []string
+
+// buttonAccess to reguły dla przycisków, które nie są komendami:
+// "zatwierdz" to przyciski kolejki moderacji.
+var buttonAccess = []string{"zatwierdz"}
+
+// defaultAccess to reguły obowiązujące, dopóki serwer ich nie nadpisze.
+// Uzupełnia je rejestr komend; komendy spoza mapy są otwarte.
+var defaultAccess = map[string]AccessRule{
+	"zatwierdz": {Permissions: discordgo.PermissionManageMessages},
+}
+
+// permissionNames to uprawnienia Discorda, które można wpisać w !uprawnienia.
+var permissionNames = []struct {
+	name  string
+	label string
+	bit   int64
+}{
+	{"administrator", "Administrator", discordgo.PermissionAdministrator},
+	{"zarzadzanie_serwerem", "Zarządzanie serwerem", discordgo.PermissionManageGuild},
+	{"zarzadzanie_kanalami", "Zarządzanie kanałami", discordgo.PermissionManageChannels},
+	{"zarzadzanie_rolami", "Zarządzanie rolami", discordgo.PermissionManageRoles},
+	{"zarzadzanie_wiadomosciami", "Zarządzanie wiadomościami", discordgo.PermissionManageMessages},
+}
+
+// commandName wyciąga z treści nazwę komendy bez prefiksu serwera, po
+// rozwinięciu aliasów. Pusty prefix oznacza treść już bez prefiksu.
+func commandName(content, prefix string) string {
+	if prefix != "" {
+		content = strings.TrimPrefix(content, prefix)
+	}
+	name, _, _ := strings.Cut(content, " ")
+	name = strings.ToLower(name)
+	if cmd, ok := commandIndex[name]; ok {
+		return cmd.Name
+	}
+	return name
+}
+
+func accessRule(guildID, command string) (AccessRule, error) {
+	access, err := store.CommandAccess(guildID)
+	if err != nil {
+		return AccessRule{}, err
+	}
+	if rule, ok := access[command]; ok {
+		return rule, nil
+	}
+	return defaultAccess[command], nil
+}
+
+// messagePermissions liczy uprawnienia autora wiadomości na jej kanale:
+// najpierw z cache, a gdy serwera jeszcze w nim nie ma - przez API.
+func messagePermissions(s *discordgo.Session, m *discordgo.MessageCreate) int64 {
+	perms, err := s.State.MessagePermissions(m.Message)
+	if err != nil {
+		perms, err = s.UserChannelPermissions(m.Author.ID, m.ChannelID)
+		if err != nil {
+			log.Println("permissions error:", err)
+		}
+	}
+	return perms
+}
+
+// checkAccess sprawdza dostęp do komendy i przy odmowie zwraca gotowy
+// komunikat dla użytkownika.
+func checkAccess(guildID, command string, roles []string, perms int64) (bool, string) {
+	rule, err := accessRule(guildID, command)
+	if err != nil {
+		log.Println("access rule error:", err)
+		return false, "❌ Nie udało się sprawdzić uprawnień, spróbuj ponownie."
+	}
+	if rule.allows(roles, perms) {
+		return true, ""
+	}
+	return false, fmt.Sprintf("⛔ Nie masz dostępu do %s%s. Potrzebujesz: %s.", commandPrefix(guildID), command, describeRule(rule))
+}
+
+// canRun sprawdza dostęp autora wiadomości i w razie odmowy mówi mu, czego brakuje.
+func canRun(s *discordgo.Session, m *discordgo.MessageCreate, command string) bool {
+	var roles []string
+	if m.Member != nil {
+		roles = m.Member.Roles
+	}
+	ok, msg := checkAccess(m.GuildID, command, roles, messagePermissions(s, m))
+	if !ok {
+		s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+			Content:         msg,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		})
+	}
+	return ok
+}
+
+func describeRule(rule AccessRule) string {
+	if rule.open() {
+		return "wszyscy"
+	}
+	var parts []string
+	for _, role := range rule.Roles {
+		parts = append(parts, "<@&"+role+">")
+	}
+	var labels []string
+	for _, p := range permissionNames {
+		if rule.Permissions&p.bit != 0 {
+			labels = append(labels, p.label)
+		}
+	}
+	if len(labels) > 0 {
+		parts = append(parts, "uprawnienie "+strings.Join(labels, " + "))
+	}
+	return strings.Join(parts, " albo ")
+}
+
+// parseAccessRule rozbiera argumenty !uprawnienia <komenda> ...: wzmianki
+// ról i nazwy uprawnień, albo jedno ze słów "wszyscy" / "domyslne" (nil).
+func parseAccessRule(args []string) (*AccessRule, error) {
+	if len(args) == 1 {
+		switch foldText(args[0]) {
+		case "wszyscy":
+			return &AccessRule{Everyone: true}, nil
+		case "domyslne":
+			return nil, nil
+		}
+	}
+	rule := &AccessRule{}
+	for _, arg := range args {
+		if id, ok := strings.CutPrefix(arg, "<@&"); ok && strings.HasSuffix(id, ">") {
+			rule.Roles = append(rule.Roles, strings.TrimSuffix(id, ">"))
+			continue
+		}
+		found := false
+		for _, p := range permissionNames {
+			if foldText(arg) == p.name {
+				rule.Permissions |= p.bit
+				found = true
+			}
+		}
+		if !found {
+			names := make([]string, len(permissionNames))
+			for i, p := range permissionNames {
+				names[i] = p.name
+			}
+			return nil, fmt.Errorf("nie rozumiem %q - podaj @role albo uprawnienia: %s", arg, strings.Join(names, ", "))
+		}
+	}
+	return rule, nil
+}
+
+// handleAccess obsługuje !uprawnienia [komenda wszyscy|domyslne|@rola... uprawnienie...].
+func handleAccess(ctx *commandContext, args string) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		sendAccessList(ctx)
+		return
+	}
+	command := commandName(fields[0], ctx.Prefix)
+	if !slices.Contains(accessCommands, command) {
+		ctx.reply(fmt.Sprintf("❌ Nie znam komendy %s. Listę pokaże %s", fields[0], ctx.cmd("uprawnienia")))
+		return
+	}
+	if len(fields) == 1 {
+		ctx.reply("❌ Użycie: " + ctx.cmd("uprawnienia") + " <komenda> wszyscy|domyslne|@rola... [uprawnienie...]")
+		return
+	}
+	rule, err := parseAccessRule(fields[1:])
+	if err != nil {
+		ctx.reply("❌ " + err.Error())
+		return
+	}
+	if err := store.SetCommandAccess(ctx.GuildID, command, rule); err != nil {
+		ctx.storeError(ctx.cmd("uprawnienia"), err)
+		return
+	}
+	effective := defaultAccess[command]
+	if rule != nil {
+		effective = *rule
+	}
+	ctx.reply(fmt.Sprintf("✅ %s: %s", ctx.cmd(command), describeRule(effective)))
+}
+
+func sendAccessList(ctx *commandContext) {
+	access, err := store.CommandAccess(ctx.GuildID)
+	if err != nil {
+		ctx.storeError(ctx.cmd("uprawnienia"), err)
+		return
+	}
+	var b strings.Builder
+	b.WriteString("**🔐 Dostęp do komend** (administratorzy mają dostęp do wszystkich):\n\n")
+	for _, command := range accessCommands {
+		rule, custom := access[command]
+		if !custom {
+			rule = defaultAccess[command]
+		}
+		b.WriteString(fmt.Sprintf("%s - %s", ctx.cmd(command), describeRule(rule)))
+		if custom {
+			b.WriteString(" *(zmienione)*")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(fmt.Sprintf("\nZmień: %[1]s <komenda> @rola zarzadzanie_wiadomosciami, %[1]s <komenda> wszyscy albo domyslne", ctx.cmd("uprawnienia")))
+	ctx.reply(b.String())
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxChoices to limit podpowiedzi w jednej odpowiedzi Discorda.
+const maxChoices = 25
+
+// autocompleters podpowiadają wartości opcji po nazwie opcji; ta sama opcja
+// (np. "tag") działa tak samo we wszystkich komendach ukośnikowych.
+var autocompleters = map[string]func(guildID, typed string) ([]*discordgo.ApplicationCommandOptionChoice, error){
+	"numer":  quoteChoices,
+	"tag":    tagChoices,
+	"tagi":   tagListChoices,
+	"ticker": tickerChoices,
+}
+
+// handleAutocomplete odpowiada podpowiedziami dla opcji, którą użytkownik
+// właśnie wpisuje.
+func handleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var focused *discordgo.ApplicationCommandInteractionDataOption
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Focused {
+			focused = opt
+		}
+	}
+	if focused == nil {
+		return
+	}
+	complete, ok := autocompleters[focused.Name]
+	if !ok {
+		return
+	}
+	// W trakcie pisania Discord przysyła wpisany tekst, także dla opcji liczbowych.
+	choices, err := complete(i.GuildID, strings.TrimSpace(fmt.Sprint(focused.Value)))
+	if err != nil {
+		log.Println("autocomplete error:", err)
+	}
+	if len(choices) > maxChoices {
+		choices = choices[:maxChoices]
+	}
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+	if err != nil {
+		log.Println("autocomplete respond error:", err)
+	}
+}
+
+// quoteChoices podpowiada cytaty po numerze albo po słowach z treści,
+// autora i tagów; bez wpisanego tekstu - najnowsze.
+func quoteChoices(guildID, typed string) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	quotes, err := store.Quotes(guildID)
+	if err != nil {
+		return nil, err
+	}
+	terms := searchTerms(typed)
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, q := range slices.Backward(quotes) {
+		if len(terms) > 0 && !strings.HasPrefix(strconv.Itoa(q.ID), typed) && !matchesSearch(q, terms) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncateRunes(fmt.Sprintf("#%d %s", q.ID, strings.Join(strings.Fields(q.Text), " ")), 100),
+			Value: q.ID,
+		})
+		if len(choices) == maxChoices {
+			break
+		}
+	}
+	return choices, nil
+}
+
+// guildTags zwraca tagi serwera od najczęściej używanych.
+func guildTags(guildID string) ([]string, error) {
+	quotes, err := store.Quotes(guildID)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, q := range quotes {
+		for _, tag := range q.Tags {
+			counts[tag]++
+		}
+	}
+	tags := make([]string, 0, len(counts))
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	slices.SortFunc(tags, func(a, b string) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	return tags, nil
+}
+
+func tagChoices(guildID, typed string) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	tags, err := guildTags(guildID)
+	if err != nil {
+		return nil, err
+	}
+	typed = foldText(normalizeTag(typed))
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, tag := range tags {
+		if strings.Contains(foldText(tag), typed) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: "#" + tag, Value: tag})
+		}
+	}
+	return choices, nil
+}
+
+// tagListChoices podpowiada ostatni tag z listy, zostawiając wpisane wcześniej.
+func tagListChoices(guildID, typed string) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	fields := strings.Fields(strings.ReplaceAll(typed, ",", " "))
+	last := ""
+	if len(fields) > 0 && !strings.HasSuffix(typed, " ") {
+		last, fields = fields[len(fields)-1], fields[:len(fields)-1]
+	}
+	choices, err := tagChoices(guildID, last)
+	if err != nil {
+		return nil, err
+	}
+	var done []string
+	for _, f := range fields {
+		done = append(done, normalizeTag(f))
+	}
+	var out []*discordgo.ApplicationCommandOptionChoice
+	for _, c := range choices {
+		tag := c.Value.(string)
+		if slices.Contains(done, tag) {
+			continue
+		}
+		value := strings.Join(append(slices.Clone(done), tag), " ")
+		if len(value) > 100 {
+			continue
+		}
+		out = append(out, &discordgo.ApplicationCommandOptionChoice{Name: value, Value: value})
+	}
+	return out, nil
+}
+
+func tickerChoices(guildID, typed string) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, ticker := range gemTickers {
+		if strings.Contains(strings.ToUpper(ticker), strings.ToUpper(typed)) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: ticker, Value: ticker})
+		}
+	}
+	return choices, nil
+}
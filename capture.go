@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// saveMessageCommand to nazwa pozycji "Aplikacje" w menu kontekstowym wiadomości.
+const saveMessageCommand = "Save as złota myśl"
+
+// quoteFromMessage robi cytat z cudzej wiadomości: treść, autor (nazwa
+// na serwerze) i link do oryginału jako źródło.
+func quoteFromMessage(s *discordgo.Session, guildID string, msg *discordgo.Message) (Quote, error) {
+	text := strings.TrimSpace(msg.Content)
+	if text == "" {
+		return Quote{}, errors.New("ta wiadomość nie ma tekstu do zapisania")
+	}
+	return Quote{
+		Text:   text,
+		Author: memberName(s, guildID, msg.Author, msg.Member),
+		Source: messageLink(guildID, msg.ChannelID, msg.ID),
+	}, nil
+}
+
+// memberName zwraca pseudonim z serwera, a bez niego nazwę wyświetlaną.
+func memberName(s *discordgo.Session, guildID string, user *discordgo.User, member *discordgo.Member) string {
+	if member == nil {
+		member, _ = s.State.Member(guildID, user.ID)
+	}
+	if member != nil && member.Nick != "" {
+		return member.Nick
+	}
+	return user.DisplayName()
+}
+
+// handleSave obsługuje !zapisz [#tag ...] [--force] wysłane jako odpowiedź
+// na wiadomość, którą chcemy zachować.
+func handleSave(ctx *commandContext, args string) {
+	m := ctx.message
+	target := m.ReferencedMessage
+	if target == nil && m.MessageReference != nil {
+		target, _ = ctx.s.ChannelMessage(m.MessageReference.ChannelID, m.MessageReference.MessageID)
+	}
+	if target == nil {
+		ctx.reply("❌ Odpowiedz na wiadomość, którą chcesz zapisać, i napisz w odpowiedzi " + ctx.cmd("zapisz"))
+		return
+	}
+	args, force := cutForceFlag(args)
+	tags, rest := parseTags(args)
+	if rest != "" {
+		ctx.reply("❌ Użycie: " + ctx.cmd("zapisz") + " [#tag ...] [--force] jako odpowiedź na wiadomość")
+		return
+	}
+	quote, err := quoteFromMessage(ctx.s, ctx.GuildID, target)
+	if err != nil {
+		ctx.reply("❌ " + err.Error())
+		return
+	}
+	quote.Tags = tags
+	reply, _ := submitQuote(ctx.s, ctx.GuildID, ctx.UserID, ctx.cmd("zapisz"), quote, force)
+	ctx.reply(reply)
+}
+
+func handleSaveMessageCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if ok, msg := checkInteractionAccess(i, "zapisz"); !ok {
+		respondEphemeral(s, i, msg)
+		return
+	}
+	data := i.ApplicationCommandData()
+	var target *discordgo.Message
+	if data.Resolved != nil {
+		target = data.Resolved.Messages[data.TargetID]
+	}
+	if target == nil {
+		respondEphemeral(s, i, "❌ Nie widzę tej wiadomości.")
+		return
+	}
+	quote, err := quoteFromMessage(s, i.GuildID, target)
+	if err != nil {
+		respondEphemeral(s, i, "❌ "+err.Error())
+		return
+	}
+	reply, ok := submitQuote(s, i.GuildID, interactionUser(i), commandPrefix(i.GuildID)+"zapisz", quote, false)
+	if !ok {
+		respondEphemeral(s, i, reply)
+		return
+	}
+	respond(s, i, reply)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// isSnowflake sprawdza, czy s wygląda jak ID Discorda (same cyfry).
+func isSnowflake(s string) bool {
+	if len(s) < 15 || len(s) > 21 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseChannelArg przyjmuje wzmiankę kanału (<#123>), surowe ID albo "tutaj".
+func parseChannelArg(arg, currentChannelID string) (string, bool) {
+	arg = strings.TrimSpace(arg)
+	if arg == "" || strings.EqualFold(arg, "tutaj") {
+		return currentChannelID, true
+	}
+	if id, ok := strings.CutPrefix(arg, "<#"); ok {
+		arg = strings.TrimSuffix(id, ">")
+	}
+	return arg, isSnowflake(arg)
+}
+
+// checkChannel sprawdza, że kanał istnieje na serwerze guildID, jest
+// tekstowy i bot ma na nim uprawnienia need. Błąd nadaje się dla użytkownika.
+func checkChannel(s *discordgo.Session, guildID, channelID string, need int64) error {
+	ch, err := s.State.Channel(channelID)
+	if err != nil {
+		if ch, err = s.Channel(channelID); err != nil {
+			log.Println("channel lookup error:", err)
+			return fmt.Errorf("nie widzę kanału %s", channelID)
+		}
+	}
+	if ch.GuildID != guildID {
+		return errors.New("ten kanał jest na innym serwerze")
+	}
+	if ch.Type != discordgo.ChannelTypeGuildText && ch.Type != discordgo.ChannelTypeGuildNews {
+		return fmt.Errorf("<#%s> nie jest kanałem tekstowym", channelID)
+	}
+	perms, err := s.UserChannelPermissions(s.State.User.ID, channelID)
+	if err != nil {
+		log.Println("channel permissions error:", err)
+		return fmt.Errorf("nie mogę sprawdzić swoich uprawnień na <#%s>", channelID)
+	}
+	if perms&need != need {
+		return fmt.Errorf("nie mogę pisać na <#%s> - daj mi uprawnienia %s", channelID, describePermissions(need))
+	}
+	return nil
+}
+
+// channelPermissionLabels nazywa uprawnienia potrzebne botowi na kanałach.
+var channelPermissionLabels = []struct {
+	bit   int64
+	label string
+}{
+	{discordgo.PermissionViewChannel, "Wyświetlanie kanału"},
+	{discordgo.PermissionSendMessages, "Wysyłanie wiadomości"},
+	{discordgo.PermissionAttachFiles, "Załączanie plików"},
+}
+
+func describePermissions(perms int64) string {
+	var labels []string
+	for _, p := range channelPermissionLabels {
+		if perms&p.bit != 0 {
+			labels = append(labels, p.label)
+		}
+	}
+	return strings.Join(labels, ", ")
+}
+
+// selectChannel rozbiera argument komendy, sprawdza kanał i wysyła na niego
+// testowy post. Zwraca ID kanału albo "" po zgłoszeniu problemu autorowi.
+func selectChannel(ctx *commandContext, command, arg string, need int64, testPost string) string {
+	channelID, ok := parseChannelArg(arg, ctx.ChannelID)
+	if !ok {
+		ctx.reply(fmt.Sprintf("❌ Podaj kanał jako #wzmiankę, ID albo słowo tutaj, np. %s #ogolny", command))
+		return ""
+	}
+	if err := checkChannel(ctx.s, ctx.GuildID, channelID, need); err != nil {
+		ctx.reply("❌ " + err.Error())
+		return ""
+	}
+	// Na bieżącym kanale test jest zarazem odpowiedzią na komendę.
+	var err error
+	if channelID == ctx.ChannelID {
+		_, err = ctx.reply(testPost)
+	} else {
+		_, err = ctx.s.ChannelMessageSend(channelID, testPost)
+	}
+	if err != nil {
+		log.Printf("%s test post error: %v", command, err)
+		ctx.reply(fmt.Sprintf("❌ Nie udało się wysłać testowej wiadomości na <#%s>, nic nie zmieniam.", channelID))
+		return ""
+	}
+	return channelID
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestParseChannelArg(t *testing.T) {
+	const current = "111111111111111111"
+	tests := []struct {
+		arg  string
+		want string
+		ok   bool
+	}{
+		{"", current, true},
+		{"tutaj", current, true},
+		{" TUTAJ ", current, true},
+		{"<#222222222222222222>", "222222222222222222", true},
+		{"222222222222222222", "222222222222222222", true},
+		{"<#12>", "12", false},
+		{"bot", "bot", false},
+		{"<@222222222222222222>", "<@222222222222222222>", false},
+		{"2222222222222222222222", "2222222222222222222222", false},
+	}
+	for _, tc := range tests {
+		got, ok := parseChannelArg(tc.arg, current)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("parseChannelArg(%q) = %q, %v, chcę %q, %v", tc.arg, got, ok, tc.want, tc.ok)
+		}
+	}
+}
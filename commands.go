@@ -0,0 +1,394 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// command to jedna komenda z prefiksem. Z tych pól powstaje też !pomoc, domyślny
+// dostęp i lista w !uprawnienia.
+type command struct {
+	Name    string
+	Aliases []string
+	// Args to składnia argumentów, np. "<numer> [wersja]".
+	Args        string
+	Description string
+	// Details pojawia się dopiero w !pomoc <komenda>.
+	Details string
+	// Access to dostęp, dopóki serwer go nie zmieni; pusta reguła = wszyscy.
+	Access AccessRule
+	Run    func(ctx *commandContext, args string)
+}
+
+var (
+	// commands to rejestr komend w kolejności z !pomoc.
+	commands []*command
+	// commandIndex prowadzi od nazwy albo aliasu do komendy.
+	commandIndex = map[string]*command{}
+)
+
+var (
+	manageMessages = AccessRule{Permissions: discordgo.PermissionManageMessages}
+	manageGuild    = AccessRule{Permissions: discordgo.PermissionManageGuild}
+)
+
+// Rejestr wypełniamy w init, bo !pomoc sama do niego zagląda.
+func init() {
+	commands = []*command{
+		{
+			Name:        "zlotamysl",
+			Aliases:     []string{"zm"},
+			Args:        "[tag]",
+			Description: "Wyświetl losową złotą myśl (opcjonalnie z danego tagu)",
+			Run: func(ctx *commandContext, args string) {
+				sendRandomQuote(ctx, args)
+			},
+		},
+		{
+			Name:        "dodaj",
+			Args:        "[#tag ...] <tekst> [| autor | źródło]",
+			Description: "Dodaj nową złotą myśl",
+			Details:     "Bardzo podobna złota myśl blokuje dodanie - dopisz --force, żeby dodać mimo to. Przy włączonej !moderacja myśl czeka na akceptację. Długie, wielolinijkowe myśli wygodniej dodać przez /dodaj bez treści - otworzy się okno z podglądem.",
+			Run: func(ctx *commandContext, args string) {
+				handleAdd(ctx, args)
+			},
+		},
+		{
+			Name:        "zapisz",
+			Args:        "[#tag ...]",
+			Description: "Wyślij jako odpowiedź na wiadomość, żeby zapisać ją jako złotą myśl",
+			Details:     "To samo robi menu wiadomości → Aplikacje → Zapisz jako złotą myśl. --force pomija sprawdzanie powtórek.",
+			Run: func(ctx *commandContext, args string) {
+				handleSave(ctx, args)
+			},
+		},
+		{
+			Name:        "usun",
+			Args:        "<numer>",
+			Description: "Przenieś złotą myśl do kosza",
+			Details:     "Numery z listy nie zmieniają się po usunięciu innych myśli.",
+			Access:      manageMessages,
+			Run: func(ctx *commandContext, args string) {
+				handleDelete(ctx, args)
+			},
+		},
+		{
+			Name:        "kosz",
+			Description: "Pokaż usunięte złote myśli",
+			Run: func(ctx *commandContext, args string) {
+				sendTrashList(ctx)
+			},
+		},
+		{
+			Name:        "przywroc",
+			Args:        "<numer>",
+			Description: "Przywróć złotą myśl z kosza",
+			Access:      manageMessages,
+			Run: func(ctx *commandContext, args string) {
+				handleRestore(ctx, args)
+			},
+		},
+		{
+			Name:        "edytuj",
+			Args:        "<numer> <nowy tekst>",
+			Description: "Popraw treść złotej myśli",
+			Details:     "Zamiast treści możesz zmienić autora, tagi albo źródło: --autor <autor>, --tagi #a #b, --zrodlo <źródło>.",
+			Access:      manageMessages,
+			Run: func(ctx *commandContext, args string) {
+				handleEdit(ctx, args)
+			},
+		},
+		{
+			Name:        "wersje",
+			Args:        "<numer>",
+			Description: "Pokaż poprzednie wersje złotej myśli",
+			Run: func(ctx *commandContext, args string) {
+				handleRevisions(ctx, args)
+			},
+		},
+		{
+			Name:        "cofnij",
+			Args:        "<numer> [wersja]",
+			Description: "Przywróć poprzednią wersję złotej myśli",
+			Access:      manageMessages,
+			Run: func(ctx *commandContext, args string) {
+				handleRevert(ctx, args)
+			},
+		},
+		{
+			Name:        "lista",
+			Args:        "[tag]",
+			Description: "Pokaż wszystkie złote myśli (albo tylko z danym tagiem)",
+			Run: func(ctx *commandContext, args string) {
+				sendPaginatedList(ctx, args)
+			},
+		},
+		{
+			Name:        "szukaj",
+			Args:        "<fraza>",
+			Description: "Szukaj w treści, autorach i tagach",
+			Details:     "Wielkość liter i polskie znaki nie mają znaczenia.",
+			Run: func(ctx *commandContext, args string) {
+				sendSearchResults(ctx, args)
+			},
+		},
+		{
+			Name:        "top",
+			Description: "Najlepiej oceniane złote myśli (głosy 👍/👎)",
+			Run: func(ctx *commandContext, args string) {
+				sendRanking(ctx, true)
+			},
+		},
+		{
+			Name:        "flop",
+			Description: "Najgorzej oceniane złote myśli (głosy 👍/👎)",
+			Run: func(ctx *commandContext, args string) {
+				sendRanking(ctx, false)
+			},
+		},
+		{
+			Name:        "historia",
+			Args:        "[n|RRRR-MM-DD]",
+			Description: "Ostatnie złote myśli dnia albo myśl dnia z podanej daty",
+			Run: func(ctx *commandContext, args string) {
+				handleHistory(ctx, args)
+			},
+		},
+		{
+			Name:        "eksport",
+			Args:        "[json|csv|md]",
+			Description: "Pobierz wszystkie złote myśli jako plik",
+			Details:     "json i csv wczytasz z powrotem przez !import; md jest tylko do czytania.",
+			Run: func(ctx *commandContext, args string) {
+				handleExport(ctx, args)
+			},
+		},
+		{
+			Name:        "import",
+			Args:        "[scal|zastap]",
+			Description: "Wczytaj złote myśli z dołączonego pliku .json, .csv albo .md",
+			Details:     "scal dopisuje nowe myśli i pomija powtórki, zastap przenosi dotychczasowe do kosza.",
+			Access:      manageGuild,
+			Run: func(ctx *commandContext, args string) {
+				handleImport(ctx, args)
+			},
+		},
+		{
+			Name:        "wagi",
+			Args:        "on|off",
+			Description: "Losuj w !zm częściej złote myśli z lepszym wynikiem",
+			Access:      manageGuild,
+			Run: func(ctx *commandContext, args string) {
+				handleWeighting(ctx, args)
+			},
+		},
+		{
+			Name:        "embedy",
+			Args:        "on|off",
+			Description: "Wysyłaj złote myśli, pogodę i wykres ETF jako embedy albo zwykły tekst",
+			Access:      manageGuild,
+			Run: func(ctx *commandContext, args string) {
+				handleEmbeds(ctx, args)
+			},
+		},
+		{
+			Name:        "tagdnia",
+			Args:        "[tag]",
+			Description: "Losuj myśl dnia tylko z danego tagu (bez tagu - ze wszystkich)",
+			Access:      manageGuild,
+			Run: func(ctx *commandContext, args string) {
+				handleDailyTag(ctx, args)
+			},
+		},
+		{
+			Name:        "kanal",
+			Args:        "[#kanał|ID|tutaj]",
+			Description: "Ustaw kanał dla codziennych myśli o 9:00",
+			Access:      manageGuild,
+			Run: func(ctx *commandContext, args string) {
+				handleChannel(ctx, args)
+			},
+		},
+		{
+			Name:        "moderacja",
+			Args:        "[#kanał|tutaj|off]",
+			Description: "Nowe złote myśli czekają na akceptację na wskazanym kanale",
+			Details:     "Bez argumentu pokazuje, czy moderacja jest włączona. Kto może akceptować, ustala !uprawnienia zatwierdz.",
+			Access:      manageGuild,
+			Run: func(ctx *commandContext, args string) {
+				handleModerationSetting(ctx, args)
+			},
+		},
+		{
+			Name:        "uprawnienia",
+			Args:        "[komenda wszyscy|domyslne|@rola... uprawnienie...]",
+			Description: "Pokaż albo zmień, kto może używać komend",
+			Access:      manageGuild,
+			Run: func(ctx *commandContext, args string) {
+				handleAccess(ctx, args)
+			},
+		},
+		{
+			Name:        "gem",
+			Args:        "[ticker]",
+			Description: "Wygeneruj wykres ETF z ostatniego roku",
+			Details:     "Z tickerem rysuje tylko jeden fundusz. Dostępne: " + strings.Join(gemTickers, ", ") + ".",
+			Run: func(ctx *commandContext, args string) {
+				handleGem(ctx, args)
+			},
+		},
+		{
+			Name:        "gemsubscribe",
+			Args:        "[#kanał|ID|tutaj]",
+			Description: "Zapisz się na miesięczny wykres ETF (ostatni dzień miesiąca, 10:00)",
+			Details:     "Na tym samym kanale codziennie o 19:00 pojawia się prognoza pogody na jutro.",
+			Run: func(ctx *commandContext, args string) {
+				handleGemSubscribe(ctx, args)
+			},
+		},
+		{
+			Name:        "pogoda",
+			Description: "Prognoza pogody na jutro",
+			Run: func(ctx *commandContext, args string) {
+				handleWeather(ctx)
+			},
+		},
+		{
+			Name:        "prefiks",
+			Args:        "[znak|domyslny]",
+			Description: "Pokaż albo zmień prefiks komend na tym serwerze",
+			Details:     "Wzmianka bota działa zawsze jako prefiks, np. @bot zm - także gdy zapomnisz nowego prefiksu.",
+			Access:      manageGuild,
+			Run: func(ctx *commandContext, args string) {
+				handlePrefix(ctx, args)
+			},
+		},
+		{
+			Name:        "pomoc",
+			Args:        "[komenda]",
+			Description: "Pokaż listę komend albo szczegóły jednej z nich",
+			Run: func(ctx *commandContext, args string) {
+				sendHelp(ctx, args)
+			},
+		},
+	}
+	for _, cmd := range commands {
+		commandIndex[cmd.Name] = cmd
+		for _, alias := range cmd.Aliases {
+			commandIndex[alias] = cmd
+		}
+		accessCommands = append(accessCommands, cmd.Name)
+		if !cmd.Access.open() {
+			defaultAccess[cmd.Name] = cmd.Access
+		}
+	}
+	accessCommands = append(accessCommands, buttonAccess...)
+}
+
+// dispatchCommand uruchamia komendę z wiadomości (już bez prefiksu).
+// Nieznane komendy pomija, żeby nie gryźć się z innymi botami.
+func dispatchCommand(s *discordgo.Session, m *discordgo.MessageCreate, content string) {
+	name := commandName(content, "")
+	cmd, ok := commandIndex[name]
+	if !ok {
+		return
+	}
+	if !canRun(s, m, cmd.Name) {
+		return
+	}
+	_, args, _ := strings.Cut(content, " ")
+	cmd.Run(messageContext(s, m), strings.TrimSpace(args))
+}
+
+func (c *command) usage(prefix string) string {
+	if c.Args == "" {
+		return prefix + c.Name
+	}
+	return prefix + c.Name + " " + c.Args
+}
+
+// sendHelp obsługuje !pomoc [komenda].
+func sendHelp(ctx *commandContext, args string) {
+	if args != "" {
+		sendCommandHelp(ctx, args)
+		return
+	}
+	prefix := ctx.Prefix
+	var b strings.Builder
+	b.WriteString("**🌟 Złote Myśli Bot - Komendy:**\n\n")
+	for _, cmd := range commands {
+		b.WriteString(cmd.usage(prefix))
+		for _, alias := range cmd.Aliases {
+			b.WriteString(" (albo " + prefix + alias + ")")
+		}
+		b.WriteString(" - " + withPrefix(cmd.Description, prefix) + "\n")
+	}
+	var slash []string
+	for _, sc := range slashCommands {
+		slash = append(slash, "/"+sc.Name)
+	}
+	b.WriteString(fmt.Sprintf("\nSzczegóły: %spomoc <komenda>. Zamiast prefiksu możesz oznaczyć bota, np. @%s zm.", prefix, ctx.s.State.User.Username))
+	b.WriteString("\nJako komendy ukośnikowe działają też: " + strings.Join(slash, ", "))
+	for _, page := range splitMessage(b.String(), 2000) {
+		ctx.reply(page)
+	}
+}
+
+func sendCommandHelp(ctx *commandContext, name string) {
+	prefix := ctx.Prefix
+	cmd, ok := commandIndex[commandName(name, prefix)]
+	if !ok {
+		ctx.reply(fmt.Sprintf("❌ Nie znam komendy %s. Wszystkie pokaże %spomoc", name, prefix))
+		return
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("**%s**\n%s", cmd.usage(prefix), withPrefix(cmd.Description, prefix)))
+	if cmd.Details != "" {
+		b.WriteString("\n" + withPrefix(cmd.Details, prefix))
+	}
+	if len(cmd.Aliases) > 0 {
+		b.WriteString("\nSkrót: " + prefix + strings.Join(cmd.Aliases, ", "+prefix))
+	}
+	for _, sc := range slashCommands {
+		if commandName(sc.Name, "") == cmd.Name {
+			b.WriteString("\nDziała też jako /" + sc.Name)
+		}
+	}
+	if rule, err := accessRule(ctx.GuildID, cmd.Name); err == nil {
+		b.WriteString("\nKto może: " + describeRule(rule))
+	}
+	ctx.reply(b.String())
+}
+
+// commandRef to odwołanie do komendy w opisach z rejestru, np. "!moderacja".
+var commandRef = regexp.MustCompile(`!([a-z]+)`)
+
+// withPrefix zamienia w opisie "!" przed nazwami komend na prefiks serwera.
+func withPrefix(text, prefix string) string {
+	return commandRef.ReplaceAllStringFunc(text, func(ref string) string {
+		if _, ok := commandIndex[ref[1:]]; !ok {
+			return ref
+		}
+		return prefix + ref[1:]
+	})
+}
+
+// splitMessage tnie tekst na kawałki po limit znaków, dzieląc na liniach.
+func splitMessage(text string, limit int) []string {
+	var parts []string
+	var cur strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if cur.Len() > 0 && cur.Len()+len(line) > limit {
+			parts = append(parts, cur.String())
+			cur.Reset()
+		}
+		cur.WriteString(line)
+	}
+	if cur.Len() > 0 {
+		parts = append(parts, cur.String())
+	}
+	return parts
+}
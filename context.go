@@ -0,0 +1,101 @@
+package main
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+// commandContext to jedno wywołanie komendy: z wiadomości z prefiksem albo
+// z komendy ukośnikowej. Handlery odpowiadają przez reply/send i nie muszą
+// wiedzieć, skąd przyszło wywołanie.
+type commandContext struct {
+	s         *discordgo.Session
+	GuildID   string
+	ChannelID string
+	UserID    string
+	// Prefix to prefiks komend serwera, do wskazówek w odpowiedziach.
+	Prefix string
+
+	// message to wiadomość z komendą; nil dla komend ukośnikowych. Potrzebują
+	// jej tylko komendy czytające odpowiedź albo załączniki (!zapisz, !import).
+	message *discordgo.MessageCreate
+
+	// interaction jest ustawione dla komend ukośnikowych, które zostały już
+	// potwierdzone odroczoną odpowiedzią ("bot myśli...").
+	interaction *discordgo.Interaction
+	responded   bool
+}
+
+func messageContext(s *discordgo.Session, m *discordgo.MessageCreate) *commandContext {
+	return &commandContext{s: s, GuildID: m.GuildID, ChannelID: m.ChannelID, UserID: m.Author.ID, Prefix: commandPrefix(m.GuildID), message: m}
+}
+
+func interactionContext(s *discordgo.Session, i *discordgo.InteractionCreate) *commandContext {
+	return &commandContext{
+		s:           s,
+		GuildID:     i.GuildID,
+		ChannelID:   i.ChannelID,
+		UserID:      interactionUser(i),
+		Prefix:      commandPrefix(i.GuildID),
+		interaction: i.Interaction,
+	}
+}
+
+// cmd zwraca nazwę komendy z prefiksem serwera, np. "?przywroc".
+func (c *commandContext) cmd(name string) string {
+	return c.Prefix + name
+}
+
+func (c *commandContext) slash() bool {
+	return c.interaction != nil
+}
+
+// send wysyła odpowiedź. Dla komendy ukośnikowej pierwsza odpowiedź zastępuje
+// "bot myśli...", a kolejne idą jako wiadomości uzupełniające. Domyślnie
+// nikogo nie pinguje.
+func (c *commandContext) send(msg *discordgo.MessageSend) (*discordgo.Message, error) {
+	if msg.AllowedMentions == nil {
+		msg.AllowedMentions = &discordgo.MessageAllowedMentions{}
+	}
+	if !c.slash() {
+		return c.s.ChannelMessageSendComplex(c.ChannelID, msg)
+	}
+	if !c.responded {
+		c.responded = true
+		edit := &discordgo.WebhookEdit{
+			Content:         &msg.Content,
+			Files:           msg.Files,
+			AllowedMentions: msg.AllowedMentions,
+		}
+		if msg.Components != nil {
+			edit.Components = &msg.Components
+		}
+		if msg.Embeds != nil {
+			edit.Embeds = &msg.Embeds
+		}
+		return c.s.InteractionResponseEdit(c.interaction, edit)
+	}
+	return c.s.FollowupMessageCreate(c.interaction, true, &discordgo.WebhookParams{
+		Content:         msg.Content,
+		Files:           msg.Files,
+		Components:      msg.Components,
+		Embeds:          msg.Embeds,
+		AllowedMentions: msg.AllowedMentions,
+	})
+}
+
+func (c *commandContext) reply(content string) (*discordgo.Message, error) {
+	return c.send(&discordgo.MessageSend{Content: content})
+}
+
+// storeError loguje błąd magazynu i mówi użytkownikowi, że nic się nie zapisało.
+func (c *commandContext) storeError(command string, err error) {
+	c.reply(storeErrorMessage(command, err))
+}
+
+// finish domyka komendę ukośnikową, na którą handler nic nie odpowiedział,
+// żeby Discord nie pokazywał w nieskończoność "bot myśli...".
+func (c *commandContext) finish() {
+	if c.slash() && !c.responded {
+		c.reply("✅ Gotowe.")
+	}
+}
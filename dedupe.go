@@ -0,0 +1,212 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// nearDuplicateThreshold to minimalne podobieństwo (0-1), od którego nowy
+// cytat uznajemy za powtórkę istniejącego.
+const nearDuplicateThreshold = 0.9
+
+// normalizeForCompare sprowadza tekst do małych liter bez ogonków,
+// interpunkcji i nadmiarowych spacji.
+func normalizeForCompare(text string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range foldRunes(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// similarity zwraca 1 - odległość Levenshteina / długość dłuższego tekstu.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+// distanceWithin liczy odległość Levenshteina tylko w pasie k wokół
+// przekątnej i przerywa, gdy wiadomo już, że przekroczy k. Zwraca false
+// dla odległości większej niż k.
+func distanceWithin(a, b []rune, k int) (int, bool) {
+	if max(len(a)-len(b), len(b)-len(a)) > k {
+		return 0, false
+	}
+	over := k + 1
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = min(j, over)
+	}
+	for i := 1; i <= len(a); i++ {
+		lo, hi := max(1, i-k), min(len(b), i+k)
+		if lo == 1 {
+			cur[0] = min(i, over)
+		} else {
+			cur[lo-1] = over
+		}
+		rowMin := cur[lo-1]
+		for j := lo; j <= hi; j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost, over)
+			rowMin = min(rowMin, cur[j])
+		}
+		if hi < len(b) {
+			cur[hi+1] = over
+		}
+		if rowMin > k {
+			return 0, false
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)], prev[len(b)] <= k
+}
+
+// duplicateIndex trzyma cytaty razem z ich znormalizowaną treścią, żeby
+// przy wielu sprawdzeniach (import) nie normalizować ich za każdym razem.
+type duplicateIndex struct {
+	quotes []Quote
+	texts  [][]rune
+	counts []letterCounts
+	exact  map[string]int
+}
+
+// letterCounts liczy litery a-z; wszystko inne trafia do ostatniej przegródki.
+type letterCounts [27]int
+
+func countLetters(text []rune) letterCounts {
+	var c letterCounts
+	for _, r := range text {
+		if r >= 'a' && r <= 'z' {
+			c[r-'a']++
+		} else {
+			c[26]++
+		}
+	}
+	return c
+}
+
+// minDistance to dolne ograniczenie odległości Levenshteina: każda edycja
+// zwiększa nadmiar i niedobór liter między tekstami najwyżej o jeden.
+func (c letterCounts) minDistance(o letterCounts) int {
+	more, less := 0, 0
+	for i := range c {
+		if d := c[i] - o[i]; d > 0 {
+			more += d
+		} else {
+			less -= d
+		}
+	}
+	return max(more, less)
+}
+
+func newDuplicateIndex(quotes []Quote) *duplicateIndex {
+	idx := &duplicateIndex{exact: map[string]int{}}
+	for _, q := range quotes {
+		idx.add(q)
+	}
+	return idx
+}
+
+func (idx *duplicateIndex) add(q Quote) {
+	text := normalizeForCompare(q.Text)
+	// Same znaki interpunkcyjne czy emoji normalizują się do "", a dwa
+	// puste teksty nie są duplikatami.
+	if text == "" {
+		return
+	}
+	if _, ok := idx.exact[text]; !ok {
+		idx.exact[text] = len(idx.quotes)
+	}
+	runes := []rune(text)
+	idx.quotes = append(idx.quotes, q)
+	idx.texts = append(idx.texts, runes)
+	idx.counts = append(idx.counts, countLetters(runes))
+}
+
+// find zwraca najbardziej podobny cytat, jeśli przekracza próg.
+func (idx *duplicateIndex) find(text string) (Quote, float64, bool) {
+	normalized := normalizeForCompare(text)
+	if normalized == "" {
+		return Quote{}, 0, false
+	}
+	if i, ok := idx.exact[normalized]; ok {
+		return idx.quotes[i], 1, true
+	}
+	needle := []rune(normalized)
+	counts := countLetters(needle)
+	var best Quote
+	bestScore := 0.0
+	for i, hay := range idx.texts {
+		// Próg podobieństwa to najwyżej 10% zmian względem dłuższego tekstu;
+		// pary różniące się bardziej (choćby długością) odpadają bez liczenia
+		// całej odległości.
+		longest := max(len(needle), len(hay))
+		limit := int(math.Floor((1-nearDuplicateThreshold)*float64(longest) + 1e-9))
+		if counts.minDistance(idx.counts[i]) > limit {
+			continue
+		}
+		dist, ok := distanceWithin(needle, hay, limit)
+		if !ok {
+			continue
+		}
+		if score := 1 - float64(dist)/float64(longest); score > bestScore {
+			best, bestScore = idx.quotes[i], score
+		}
+	}
+	return best, bestScore, bestScore >= nearDuplicateThreshold
+}
+
+// findDuplicate zwraca najbardziej podobny cytat, jeśli przekracza próg.
+func findDuplicate(quotes []Quote, text string) (Quote, float64, bool) {
+	return newDuplicateIndex(quotes).find(text)
+}
+
+// cutForceFlag zdejmuje --force z początku albo końca argumentów.
+func cutForceFlag(args string) (string, bool) {
+	args = strings.TrimSpace(args)
+	if args == "--force" {
+		return "", true
+	}
+	if rest, ok := strings.CutPrefix(args, "--force "); ok {
+		return strings.TrimSpace(rest), true
+	}
+	if rest, ok := strings.CutSuffix(args, " --force"); ok {
+		return strings.TrimSpace(rest), true
+	}
+	return args, false
+}
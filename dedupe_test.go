@@ -0,0 +1,88 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"kot", "kot", 1},
+		{"kot", "kit", 1 - 1.0/3},
+		{"kot", "", 0},
+		{"abcdefghij", "abcdefghi", 0.9},
+		{"żółw", "zolw", 0.25},
+	}
+	for _, tc := range tests {
+		if got := similarity(tc.a, tc.b); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("similarity(%q, %q) = %v, chcę %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+// TestDistanceWithin porównuje przycięty algorytm z pełnym Levenshteinem.
+func TestDistanceWithin(t *testing.T) {
+	words := []string{"", "a", "kot", "kto", "kotek", "motek", "abcdefghij", "abcdxfghij", "ghijabcdef"}
+	for _, a := range words {
+		for _, b := range words {
+			full := int(math.Round((1 - similarity(a, b)) * float64(max(len(a), len(b)))))
+			for k := range 5 {
+				d, ok := distanceWithin([]rune(a), []rune(b), k)
+				if ok != (full <= k) || (ok && d != full) {
+					t.Errorf("distanceWithin(%q, %q, %d) = %d, %v, pełna odległość %d", a, b, k, d, ok, full)
+				}
+			}
+		}
+	}
+}
+
+func TestFindDuplicate(t *testing.T) {
+	quotes := []Quote{
+		{ID: 1, Text: "Kto rano wstaje, temu Pan Bóg daje."},
+		{ID: 2, Text: "Nie ma róży bez kolców."},
+		{ID: 3, Text: "!!!"},
+	}
+	tests := []struct {
+		text   string
+		wantID int
+		dup    bool
+	}{
+		{"kto rano wstaje temu pan bog daje", 1, true},
+		{"Kto rano wstaje, temu Pan Bóg daj.", 1, true},
+		{"Nie ma róży bez kolca", 2, true},
+		{"Nie ma dymu bez ognia.", 0, false},
+		{"Kto późno wstaje, temu Pan Bóg nie daje.", 0, false},
+		{"???", 0, false},
+		{"", 0, false},
+	}
+	for _, tc := range tests {
+		q, _, dup := findDuplicate(quotes, tc.text)
+		if dup != tc.dup || (dup && q.ID != tc.wantID) {
+			t.Errorf("findDuplicate(%q) = #%d, %v, chcę #%d, %v", tc.text, q.ID, dup, tc.wantID, tc.dup)
+		}
+	}
+}
+
+func TestCutForceFlag(t *testing.T) {
+	tests := []struct {
+		args  string
+		want  string
+		force bool
+	}{
+		{"--force", "", true},
+		{"--force tekst", "tekst", true},
+		{"tekst --force", "tekst", true},
+		{"tekst z --force w środku", "tekst z --force w środku", false},
+		{" tekst ", "tekst", false},
+	}
+	for _, tc := range tests {
+		got, force := cutForceFlag(tc.args)
+		if got != tc.want || force != tc.force {
+			t.Errorf("cutForceFlag(%q) = %q, %v", tc.args, got, force)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseEdit rozbiera argumenty !edytuj:
+//
+//	<id> <nowy tekst>
+//	<id> --autor <autor>     (pusty autor go usuwa)
+//	<id> --tagi #a #b        (bez tagów - usuwa wszystkie)
+//	<id> --zrodlo <źródło>
+func parseEdit(ctx *commandContext, args string) (int, func(*Quote), error) {
+	idStr, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	id, ok := parseQuoteID(idStr)
+	if !ok {
+		return 0, nil, errors.New("podaj numer złotej myśli, np. " + ctx.cmd("edytuj") + " 12 nowy tekst")
+	}
+	rest = strings.TrimSpace(rest)
+	flag, value, _ := strings.Cut(rest, " ")
+	value = strings.TrimSpace(value)
+	switch flag {
+	case "--autor":
+		return id, func(q *Quote) { q.Author = value }, nil
+	case "--zrodlo":
+		return id, func(q *Quote) { q.Source = value }, nil
+	case "--tagi":
+		tags, leftover := parseTags(value)
+		if leftover != "" {
+			return 0, nil, errors.New("tagi muszą zaczynać się od #, np. " + ctx.cmd("edytuj") + " 12 --tagi #praca #motywacja")
+		}
+		return id, func(q *Quote) { q.Tags = tags }, nil
+	}
+	if rest == "" {
+		return 0, nil, errors.New("podaj nowy tekst, --autor, --tagi albo --zrodlo")
+	}
+	return id, func(q *Quote) { q.Text = rest }, nil
+}
+
+func handleEdit(ctx *commandContext, args string) {
+	id, fn, err := parseEdit(ctx, args)
+	if err != nil {
+		ctx.reply("❌ " + err.Error())
+		return
+	}
+	quote, err := store.EditQuote(ctx.GuildID, id, ctx.UserID, fn)
+	if errors.Is(err, errQuoteNotFound) {
+		ctx.reply(fmt.Sprintf("❌ Nie ma złotej myśli #%d!", id))
+		return
+	}
+	if err != nil {
+		ctx.storeError(ctx.cmd("edytuj"), err)
+		return
+	}
+	ctx.reply(formatQuote(fmt.Sprintf("✏️ Zmieniono złotą myśl #%d (poprzednia wersja: %s %d):", id, ctx.cmd("wersje"), id), quote))
+}
+
+func handleRevisions(ctx *commandContext, args string) {
+	id, ok := parseQuoteID(args)
+	if !ok {
+		ctx.reply("❌ Podaj numer złotej myśli, np. " + ctx.cmd("wersje") + " 12")
+		return
+	}
+	quote, err := store.Quote(ctx.GuildID, id)
+	var revs []QuoteRevision
+	if err == nil {
+		revs, err = store.QuoteRevisions(ctx.GuildID, id)
+	}
+	if errors.Is(err, errQuoteNotFound) {
+		ctx.reply(fmt.Sprintf("❌ Nie ma złotej myśli #%d!", id))
+		return
+	}
+	if err != nil {
+		ctx.storeError(ctx.cmd("wersje"), err)
+		return
+	}
+	if len(revs) == 0 {
+		ctx.reply(fmt.Sprintf("Złota myśl #%d nie była edytowana.", id))
+		return
+	}
+
+	const maxShown = 10
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("**🕘 Wersje złotej myśli #%d:**\n\n", id))
+	first := max(0, len(revs)-maxShown)
+	if first > 0 {
+		b.WriteString(fmt.Sprintf("(pominięto %d starszych)\n", first))
+	}
+	for i := first; i < len(revs); i++ {
+		b.WriteString(fmt.Sprintf("%d. %s\n", i+1, describeRevision(revs[i])))
+	}
+	b.WriteString(fmt.Sprintf("\nObecnie: *%s*\n", truncateRunes(quote.Text, 150)))
+	b.WriteString(fmt.Sprintf("Przywróć wersję komendą %s %d [numer wersji]", ctx.cmd("cofnij"), id))
+	ctx.reply(b.String())
+}
+
+func describeRevision(rev QuoteRevision) string {
+	line := fmt.Sprintf("*%s*", truncateRunes(rev.Text, 150))
+	if rev.Author != "" {
+		line += " — " + rev.Author
+	}
+	if len(rev.Tags) > 0 {
+		line += " " + formatTags(rev.Tags)
+	}
+	line += fmt.Sprintf(" (do %s", rev.EditedAt.Format("2006-01-02 15:04"))
+	if rev.EditedBy != "" {
+		line += fmt.Sprintf(", zmienił(a) <@%s>", rev.EditedBy)
+	}
+	return line + ")"
+}
+
+// handleRevert przywraca wersję z !wersje (domyślnie ostatnią). Samo
+// przywrócenie też trafia do historii, więc da się je cofnąć.
+func handleRevert(ctx *commandContext, args string) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 || len(fields) > 2 {
+		ctx.reply("❌ Użycie: " + ctx.cmd("cofnij") + " <numer> [wersja]")
+		return
+	}
+	id, ok := parseQuoteID(fields[0])
+	if !ok {
+		ctx.reply("❌ Nieprawidłowy numer!")
+		return
+	}
+	revs, err := store.QuoteRevisions(ctx.GuildID, id)
+	if errors.Is(err, errQuoteNotFound) {
+		ctx.reply(fmt.Sprintf("❌ Nie ma złotej myśli #%d!", id))
+		return
+	}
+	if err != nil {
+		ctx.storeError(ctx.cmd("cofnij"), err)
+		return
+	}
+	if len(revs) == 0 {
+		ctx.reply(fmt.Sprintf("Złota myśl #%d nie ma wcześniejszych wersji.", id))
+		return
+	}
+	version := len(revs)
+	if len(fields) == 2 {
+		v, err := strconv.Atoi(fields[1])
+		if err != nil || v < 1 || v > len(revs) {
+			ctx.reply(fmt.Sprintf("❌ Złota myśl #%d ma wersje 1-%d.", id, len(revs)))
+			return
+		}
+		version = v
+	}
+	rev := revs[version-1]
+	quote, err := store.EditQuote(ctx.GuildID, id, ctx.UserID, func(q *Quote) {
+		q.Text, q.Author, q.Source, q.Tags = rev.Text, rev.Author, rev.Source, rev.Tags
+	})
+	if err != nil {
+		ctx.storeError(ctx.cmd("cofnij"), err)
+		return
+	}
+	ctx.reply(formatQuote(fmt.Sprintf("↩️ Przywrócono wersję %d złotej myśli #%d:", version, id), quote))
+}
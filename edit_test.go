@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// TestEditQuoteRevisions sprawdza, że edycja odkłada poprzednią wersję i nie rusza ID.
+func TestEditQuoteRevisions(t *testing.T) {
+	forEachStore(t, func(t *testing.T, st Store) {
+		original, _ := st.Quote(testGuild, 1)
+		edited, err := st.EditQuote(testGuild, 1, "ed", func(q *Quote) {
+			q.ID = 99
+			q.Text = "poprawiona"
+			q.Tags = []string{"nowy"}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if edited.ID != 1 || edited.Text != "poprawiona" {
+			t.Errorf("EditQuote = %+v", edited)
+		}
+		st.EditQuote(testGuild, 1, "ed2", func(q *Quote) { q.Author = "ktoś" })
+		revs, err := st.QuoteRevisions(testGuild, 1)
+		if err != nil || len(revs) != 2 {
+			t.Fatalf("QuoteRevisions = %+v, %v", revs, err)
+		}
+		if revs[0].Text != original.Text || revs[0].EditedBy != "ed" {
+			t.Errorf("pierwsza wersja = %+v", revs[0])
+		}
+		if revs[1].Text != "poprawiona" || !slices.Equal(revs[1].Tags, []string{"nowy"}) || revs[1].EditedBy != "ed2" {
+			t.Errorf("druga wersja = %+v", revs[1])
+		}
+		if _, err := st.EditQuote(testGuild, 42, "ed", func(*Quote) {}); !errors.Is(err, errQuoteNotFound) {
+			t.Errorf("EditQuote(42) = %v", err)
+		}
+		if _, err := st.QuoteRevisions(testGuild, 42); !errors.Is(err, errQuoteNotFound) {
+			t.Errorf("QuoteRevisions(42) = %v", err)
+		}
+	})
+}
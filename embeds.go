@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Kolory pasków embedów.
+const (
+	quoteColor   = 0xF1C40F
+	dailyColor   = 0xE67E22
+	weatherColor = 0x3498DB
+	gemColor     = 0x2ECC71
+)
+
+// plainText mówi, czy serwer wyłączył embedy komendą !embedy off. Przy
+// błędzie odczytu zostajemy przy embedach.
+func plainText(guildID string) bool {
+	settings, err := store.Settings(guildID)
+	if err != nil {
+		log.Println("plain text setting error:", err)
+		return false
+	}
+	return settings.PlainText
+}
+
+// quoteMessage buduje wiadomość z cytatem: embed z tytułem title albo, gdy
+// serwer woli zwykły tekst, formatQuote z nagłówkiem header.
+func quoteMessage(plain bool, header, title string, color int, q Quote) *discordgo.MessageSend {
+	if plain {
+		return &discordgo.MessageSend{Content: formatQuote(header, q)}
+	}
+	return &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{quoteEmbed(title, color, q)}}
+}
+
+func quoteEmbed(title string, color int, q Quote) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: "*" + q.Text + "*",
+		Color:       color,
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Złota myśl #%d", q.ID)},
+	}
+	if q.Author != "" {
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: q.Author}
+	}
+	if strings.HasPrefix(q.Source, "https://") || strings.HasPrefix(q.Source, "http://") {
+		embed.URL = q.Source
+	}
+	if q.Source != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Źródło", Value: q.Source, Inline: true})
+	}
+	if q.SubmitterID != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Dodane przez", Value: "<@" + q.SubmitterID + ">", Inline: true})
+	}
+	if len(q.Tags) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Tagi", Value: formatTags(q.Tags), Inline: true})
+	}
+	if !q.CreatedAt.IsZero() {
+		embed.Timestamp = q.CreatedAt.Format(time.RFC3339)
+	}
+	return embed
+}
+
+// handleEmbeds obsługuje !embedy on|off.
+func handleEmbeds(ctx *commandContext, args string) {
+	var plain bool
+	switch strings.ToLower(args) {
+	case "on", "wl", "wł", "tak":
+		plain = false
+	case "off", "wyl", "wył", "nie":
+		plain = true
+	default:
+		ctx.reply("❌ Użycie: " + ctx.cmd("embedy") + " on|off")
+		return
+	}
+	err := store.UpdateSettings(ctx.GuildID, func(gs *GuildSettings) {
+		gs.PlainText = plain
+	})
+	if err != nil {
+		ctx.storeError(ctx.cmd("embedy"), err)
+		return
+	}
+	if plain {
+		ctx.reply("✅ Złote myśli, pogoda i wykres ETF będą wysyłane jako zwykły tekst.")
+	} else {
+		ctx.reply("✅ Złote myśli, pogoda i wykres ETF będą wysyłane jako embedy.")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"image/color"
@@ -23,6 +24,16 @@ import (
 
 var gemTickers = []string{"EIMI.L", "CNDX.L", "CBU0.L", "IB01.L"}
 
+// findGemTicker szuka tickera z gemTickers bez względu na wielkość liter.
+func findGemTicker(name string) (string, bool) {
+	for _, ticker := range gemTickers {
+		if strings.EqualFold(ticker, strings.TrimSpace(name)) {
+			return ticker, true
+		}
+	}
+	return "", false
+}
+
 var gemColors = map[string]color.RGBA{
 	"EIMI.L": hexColor("0000FF"),
 	"CNDX.L": hexColor("FFA500"),
@@ -30,6 +41,17 @@ var gemColors = map[string]color.RGBA{
 	"IB01.L": hexColor("FF0000"),
 }
 
+// gemSummary to końcowe stopy zwrotu z wykresu, do opisu pod obrazkiem.
+type gemSummary struct {
+	Returns  []gemReturn
+	DataTime time.Time
+}
+
+type gemReturn struct {
+	Ticker string
+	Return float64
+}
+
 type yahooChartResponse struct {
 	Chart struct {
 		Result []struct {
@@ -44,17 +66,19 @@ type yahooChartResponse struct {
 	} `json:"chart"`
 }
 
-func generateGemChart(outputPath string) error {
+// generateGemChart rysuje wykres stóp zwrotu tickers do outputPath i zwraca
+// końcowe stopy zwrotu z datą ostatnich danych.
+func generateGemChart(outputPath string, tickers []string) (gemSummary, error) {
 	loc, err := time.LoadLocation("Europe/Warsaw")
 	if err != nil {
-		return err
+		return gemSummary{}, err
 	}
 
 	end := time.Now().In(loc)
 	start := end.AddDate(-1, 0, 0)
 
 	client := &http.Client{Timeout: 20 * time.Second}
-	seriesByTicker := make(map[string]map[int64]float64, len(gemTickers))
+	seriesByTicker := make(map[string]map[int64]float64, len(tickers))
 	baseTimestamps := []int64{}
 
 	type fetchResult struct {
@@ -64,18 +88,18 @@ func generateGemChart(outputPath string) error {
 		err    error
 	}
 
-	results := make(chan fetchResult, len(gemTickers))
-	for _, ticker := range gemTickers {
+	results := make(chan fetchResult, len(tickers))
+	for _, ticker := range tickers {
 		go func(t string) {
 			ts, vals, fetchErr := fetchYahooSeries(client, t, start, end)
 			results <- fetchResult{ticker: t, ts: ts, vals: vals, err: fetchErr}
 		}(ticker)
 	}
 
-	for i := 0; i < len(gemTickers); i++ {
+	for i := 0; i < len(tickers); i++ {
 		res := <-results
 		if res.err != nil {
-			return res.err
+			return gemSummary{}, res.err
 		}
 		if len(baseTimestamps) == 0 {
 			baseTimestamps = res.ts
@@ -88,19 +112,19 @@ func generateGemChart(outputPath string) error {
 	}
 
 	if len(baseTimestamps) == 0 {
-		return fmt.Errorf("brak danych do wykresu")
+		return gemSummary{}, fmt.Errorf("brak danych do wykresu")
 	}
 
 	sort.Slice(baseTimestamps, func(i, j int) bool { return baseTimestamps[i] < baseTimestamps[j] })
 
 	times := make([]time.Time, 0, len(baseTimestamps))
-	valuesByTicker := make(map[string][]float64, len(gemTickers))
-	lastKnown := make(map[string]float64, len(gemTickers))
-	hasKnown := make(map[string]bool, len(gemTickers))
+	valuesByTicker := make(map[string][]float64, len(tickers))
+	lastKnown := make(map[string]float64, len(tickers))
+	hasKnown := make(map[string]bool, len(tickers))
 
 	for _, ts := range baseTimestamps {
 		times = append(times, time.Unix(ts, 0).In(loc))
-		for _, ticker := range gemTickers {
+		for _, ticker := range tickers {
 			if v, ok := seriesByTicker[ticker][ts]; ok && !math.IsNaN(v) {
 				lastKnown[ticker] = v
 				hasKnown[ticker] = true
@@ -116,7 +140,7 @@ func generateGemChart(outputPath string) error {
 	startIdx := 0
 	for i := range times {
 		ok := true
-		for _, ticker := range gemTickers {
+		for _, ticker := range tickers {
 			if math.IsNaN(valuesByTicker[ticker][i]) {
 				ok = false
 				break
@@ -129,24 +153,24 @@ func generateGemChart(outputPath string) error {
 	}
 
 	if startIdx >= len(times) {
-		return fmt.Errorf("brak kompletnych danych do wykresu")
+		return gemSummary{}, fmt.Errorf("brak kompletnych danych do wykresu")
 	}
 
 	times = times[startIdx:]
-	returnsByTicker := make(map[string][]float64, len(gemTickers))
+	returnsByTicker := make(map[string][]float64, len(tickers))
 	maxValue := -math.MaxFloat64
 
-	for _, ticker := range gemTickers {
+	for _, ticker := range tickers {
 		series := valuesByTicker[ticker][startIdx:]
 		base := series[0]
 		if base == 0 {
-			return fmt.Errorf("wartość bazowa dla %s równa zero", ticker)
+			return gemSummary{}, fmt.Errorf("wartość bazowa dla %s równa zero", ticker)
 		}
 		ret := make([]float64, len(series))
 		for i, v := range series {
 			val := (v/base - 1) * 100
 			if math.IsNaN(val) || math.IsInf(val, 0) {
-				return fmt.Errorf("nieprawidłowe dane zwrotu dla %s", ticker)
+				return gemSummary{}, fmt.Errorf("nieprawidłowe dane zwrotu dla %s", ticker)
 			}
 			ret[i] = val
 			if val > maxValue {
@@ -157,7 +181,7 @@ func generateGemChart(outputPath string) error {
 	}
 
 	if maxValue == -math.MaxFloat64 || math.IsNaN(maxValue) || math.IsInf(maxValue, 0) {
-		return fmt.Errorf("brak danych do wykresu")
+		return gemSummary{}, fmt.Errorf("brak danych do wykresu")
 	}
 
 	yMin := -25.0
@@ -217,8 +241,8 @@ func generateGemChart(outputPath string) error {
 	p.X.Min = xMin
 	p.X.Max = xMax + xPad
 
-	seriesLabels := make([]seriesLabel, 0, len(gemTickers))
-	for _, ticker := range gemTickers {
+	seriesLabels := make([]seriesLabel, 0, len(tickers))
+	for _, ticker := range tickers {
 		series := returnsByTicker[ticker]
 		if len(series) == 0 {
 			continue
@@ -230,7 +254,7 @@ func generateGemChart(outputPath string) error {
 		}
 		line, err := plotter.NewLine(pts)
 		if err != nil {
-			return err
+			return gemSummary{}, err
 		}
 		line.Color = gemColors[ticker]
 		line.Width = vg.Points(1.5)
@@ -262,22 +286,29 @@ func generateGemChart(outputPath string) error {
 	})
 
 	if err := ensureDir(outputPath); err != nil {
-		return err
+		return gemSummary{}, err
 	}
 
 	fmt.Println("\n============================================================")
 	fmt.Println("STOPY ZWROTU - 1 ROK:")
 	fmt.Println("============================================================")
-	for _, ticker := range gemTickers {
+	for _, ticker := range tickers {
 		series := returnsByTicker[ticker]
 		if len(series) == 0 {
 			continue
 		}
 		fmt.Printf("%-10s: %+7.2f%%\n", ticker, series[len(series)-1])
 	}
-	fmt.Println("============================================================\n")
+	fmt.Println("============================================================")
+	fmt.Println()
 
-	return p.Save(12*vg.Inch, 6*vg.Inch, outputPath)
+	summary := gemSummary{DataTime: times[len(times)-1]}
+	for _, ticker := range tickers {
+		if series := returnsByTicker[ticker]; len(series) > 0 {
+			summary.Returns = append(summary.Returns, gemReturn{Ticker: ticker, Return: series[len(series)-1]})
+		}
+	}
+	return summary, p.Save(12*vg.Inch, 6*vg.Inch, outputPath)
 }
 
 func fetchYahooSeries(client *http.Client, ticker string, start, end time.Time) ([]int64, []float64, error) {
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	defaultHistoryDays = 7
+	maxHistoryDays     = 60
+)
+
+// messageLink buduje link, który w Discordzie przenosi do wiadomości.
+func messageLink(guildID, channelID, messageID string) string {
+	return fmt.Sprintf("https://discord.com/channels/%s/%s/%s", guildID, channelID, messageID)
+}
+
+func recordDaily(guildID, date string, msg *discordgo.Message, quoteID int) {
+	err := store.RecordDaily(guildID, DailyEntry{
+		Date:      date,
+		ChannelID: msg.ChannelID,
+		QuoteID:   quoteID,
+		MessageID: msg.ID,
+		PostedAt:  time.Now(),
+	})
+	if err != nil {
+		log.Println("daily history error:", err)
+	}
+}
+
+// handleHistory obsługuje !historia [n] oraz !historia RRRR-MM-DD.
+func handleHistory(ctx *commandContext, args string) {
+	if strings.Contains(args, "-") {
+		sendDailyOn(ctx, args)
+		return
+	}
+	n := defaultHistoryDays
+	if args != "" {
+		v, err := strconv.Atoi(args)
+		if err != nil || v < 1 || v > maxHistoryDays {
+			ctx.reply(fmt.Sprintf("❌ Użycie: %[1]s [1-%[2]d] albo %[1]s RRRR-MM-DD", ctx.cmd("historia"), maxHistoryDays))
+			return
+		}
+		n = v
+	}
+	entries, err := store.DailyHistory(ctx.GuildID, n)
+	var quotes []Quote
+	if err == nil {
+		quotes, err = store.Quotes(ctx.GuildID)
+	}
+	if err != nil {
+		ctx.storeError(ctx.cmd("historia"), err)
+		return
+	}
+	if len(entries) == 0 {
+		ctx.reply("Nie było jeszcze żadnej złotej myśli dnia.")
+		return
+	}
+	byID := make(map[int]Quote, len(quotes))
+	for _, q := range quotes {
+		byID[q.ID] = q
+	}
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		text := "*(usunięta)*"
+		if q, ok := byID[e.QuoteID]; ok {
+			text = truncateRunes(q.Text, 80)
+		}
+		lines[i] = fmt.Sprintf("%s · #%d %s <%s>", e.Date, e.QuoteID, text, messageLink(ctx.GuildID, e.ChannelID, e.MessageID))
+	}
+	sendPages(ctx, "Ostatnie złote myśli dnia", lines)
+}
+
+func sendDailyOn(ctx *commandContext, date string) {
+	if _, err := time.Parse(dailyDateLayout, date); err != nil {
+		ctx.reply("❌ Podaj datę w formacie RRRR-MM-DD, np. " + ctx.cmd("historia") + " 2026-03-14")
+		return
+	}
+	entry, err := store.DailyOn(ctx.GuildID, date)
+	if errors.Is(err, errDailyNotFound) {
+		ctx.reply(fmt.Sprintf("Brak złotej myśli dnia z %s.", date))
+		return
+	}
+	if err != nil {
+		ctx.storeError(ctx.cmd("historia"), err)
+		return
+	}
+	link := messageLink(ctx.GuildID, entry.ChannelID, entry.MessageID)
+	quote, err := store.Quote(ctx.GuildID, entry.QuoteID)
+	if errors.Is(err, errQuoteNotFound) {
+		ctx.reply(fmt.Sprintf("📅 %s była złota myśl #%d, ale już jej nie ma. <%s>", date, entry.QuoteID, link))
+		return
+	}
+	if err != nil {
+		ctx.storeError(ctx.cmd("historia"), err)
+		return
+	}
+	header := fmt.Sprintf("📅 **Złota myśl dnia %s** (#%d, <%s>)", date, quote.ID, link)
+	msg := quoteMessage(plainText(ctx.GuildID), header, "📅 Złota myśl dnia "+date, dailyColor, quote)
+	if msg.Embeds != nil {
+		msg.Embeds[0].URL = link
+	}
+	ctx.send(msg)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// messageCommands to komendy z menu kontekstowego wiadomości.
+var messageCommands = []*discordgo.ApplicationCommand{
+	{
+		Name:              saveMessageCommand,
+		NameLocalizations: &map[discordgo.Locale]string{discordgo.Polish: "Zapisz jako złotą myśl"},
+		Type:              discordgo.MessageApplicationCommand,
+	},
+}
+
+// registerApplicationCommands nadpisuje globalne komendy aplikacji, więc
+// usunięte z kodu znikają też z Discorda.
+func registerApplicationCommands(s *discordgo.Session) {
+	commands := append(slices.Clone(slashCommands), messageCommands...)
+	if _, err := s.ApplicationCommandBulkOverwrite(s.State.User.ID, "", commands); err != nil {
+		log.Println("Błąd rejestracji komend aplikacji:", err)
+	}
+}
+
+// interactionCreate rozdziela interakcje: komendy aplikacji po nazwie,
+// kliknięcia przycisków po prefiksie CustomID ("mod:approve:12" trafia do
+// obsługi moderacji).
+func interactionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.GuildID == "" {
+		return
+	}
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		switch i.ApplicationCommandData().Name {
+		case saveMessageCommand:
+			handleSaveMessageCommand(s, i)
+		default:
+			handleSlashCommand(s, i)
+		}
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		handleAutocomplete(s, i)
+	case discordgo.InteractionModalSubmit:
+		kind, rest, _ := strings.Cut(i.ModalSubmitData().CustomID, ":")
+		switch kind {
+		case "add":
+			handleAddModal(s, i, rest)
+		}
+	case discordgo.InteractionMessageComponent:
+		kind, rest, _ := strings.Cut(i.MessageComponentData().CustomID, ":")
+		switch kind {
+		case "add":
+			handleAddButton(s, i, rest)
+		case "mod":
+			handleModerationButton(s, i, rest)
+		case "page":
+			handlePageButton(s, i, rest)
+		}
+	}
+}
+
+// runNonce wyróżnia to uruchomienie bota. Liczniki list i szkiców zaczynają
+// po restarcie od zera, więc bez niego stary przycisk trafiłby w cudzą sesję.
+var runNonce = strconv.FormatUint(rand.Uint64(), 36)
+
+// sessionKey zamienia numer sesji z pamięci na klucz do CustomID.
+func sessionKey(id int) string {
+	return fmt.Sprintf("%s.%d", runNonce, id)
+}
+
+// parseSessionKey odczytuje numer sesji z klucza; klucze sprzed restartu
+// są odrzucane.
+func parseSessionKey(key string) (int, bool) {
+	nonce, idStr, _ := strings.Cut(key, ".")
+	if nonce != runNonce {
+		return 0, false
+	}
+	id, err := strconv.Atoi(idStr)
+	return id, err == nil
+}
+
+// checkInteractionAccess to checkAccess dla interakcji; Discord przysyła
+// w nich gotowe uprawnienia członka na kanale.
+func checkInteractionAccess(i *discordgo.InteractionCreate, command string) (bool, string) {
+	if i.Member == nil {
+		return false, "⛔ Ta akcja działa tylko na serwerze."
+	}
+	return checkAccess(i.GuildID, command, i.Member.Roles, i.Member.Permissions)
+}
+
+// respond odpowiada na interakcję wiadomością widoczną dla wszystkich, bez pingów.
+func respond(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         content,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+	if err != nil {
+		log.Println("interaction respond error:", err)
+	}
+}
+
+// respondEphemeral odpowiada na interakcję wiadomością widoczną tylko dla klikającego.
+func respondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         content,
+			Flags:           discordgo.MessageFlagsEphemeral,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+	if err != nil {
+		log.Println("interaction respond error:", err)
+	}
+}
+
+// interactionUser zwraca ID użytkownika, który wywołał interakcję na serwerze.
+func interactionUser(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
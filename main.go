@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -18,17 +19,7 @@ import (
 	"github.com/robfig/cron/v3"
 )
 
-type Config struct {
-	Quotes         []string `json:"quotes"`
-	ChannelID      string   `json:"channel_id"`
-	GemChannelID   string   `json:"gem_channel_id"`
-	GemSubscribers []string `json:"gem_subscribers"`
-}
-
-var (
-	config     Config
-	configFile = "config.json"
-)
+var store Store
 
 func main() {
 	token := os.Getenv("DISCORD_TOKEN")
@@ -38,15 +29,31 @@ func main() {
 
 	rand.Seed(time.Now().UnixNano()) // ✅ Losowe cytaty
 
-	loadConfig()
-
 	dg, err := discordgo.New("Bot " + token)
 	if err != nil {
 		log.Fatal("Błąd tworzenia sesji:", err)
 	}
 
+	// Sesja przed połączeniem ma już REST, więc magazyn może ustalić serwer
+	// kanału ze starej konfiguracji.
+	store, err = openStore(func(channelID string) (string, error) {
+		ch, err := dg.Channel(channelID)
+		if err != nil {
+			return "", err
+		}
+		return ch.GuildID, nil
+	})
+	if err != nil {
+		log.Fatal("Błąd otwierania magazynu danych:", err)
+	}
+	defer store.Close()
+
 	dg.AddHandler(messageCreate)
-	dg.Identify.Intents = discordgo.IntentsGuildMessages
+	dg.AddHandler(messageReactionAdd)
+	dg.AddHandler(messageReactionRemove)
+	dg.AddHandler(interactionCreate)
+	// IntentsGuilds trzyma w cache role i kanały potrzebne do liczenia uprawnień.
+	dg.Identify.Intents = discordgo.IntentsGuilds | discordgo.IntentsGuildMessages | discordgo.IntentsGuildMessageReactions
 
 	// 🚀 CRON SCHEDULER zamiast tickera
 	go startCronScheduler(dg)
@@ -57,6 +64,8 @@ func main() {
 	}
 	defer dg.Close()
 
+	registerApplicationCommands(dg)
+
 	fmt.Println("Bot działa! Codzienne cytaty o 9:00 CET. Naciśnij CTRL+C aby zakończyć.")
 
 	sc := make(chan os.Signal, 1)
@@ -64,114 +73,146 @@ func main() {
 	<-sc
 }
 
-func loadConfig() {
-	data, err := os.ReadFile(configFile)
-	if err != nil {
-		config = Config{
-			Quotes: []string{
-				"Wytrwałość to klucz do sukcesu.",
-				"Każdy dzień to nowa szansa.",
-				"Wierz w siebie i swoje możliwości.",
-			},
-			ChannelID:      "",
-			GemChannelID:   "",
-			GemSubscribers: nil,
-		}
-		saveConfig()
+func messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
+	if m.Author.ID == s.State.User.ID {
+		return
+	}
+	// Stan bota jest trzymany per serwer, więc wiadomości prywatne pomijamy.
+	if m.GuildID == "" {
+		return
+	}
+
+	content, ok := parsePrefix(s, m, strings.TrimSpace(m.Content))
+	if !ok {
+		return
+	}
+	dispatchCommand(s, m, content)
+}
+
+func handleDelete(ctx *commandContext, args string) {
+	id, ok := parseQuoteID(args)
+	if !ok {
+		ctx.reply("❌ Nieprawidłowy numer!")
 		return
 	}
-	json.Unmarshal(data, &config)
+	err := store.DeleteQuote(ctx.GuildID, id, ctx.UserID)
+	switch {
+	case err == nil:
+		ctx.reply(fmt.Sprintf("🗑️ Przeniesiono złotą myśl #%d do kosza. Pomyłka? %s %d", id, ctx.cmd("przywroc"), id))
+	case errors.Is(err, errQuoteNotFound):
+		ctx.reply(fmt.Sprintf("❌ Nie ma złotej myśli #%d!", id))
+	default:
+		ctx.storeError(ctx.cmd("usun"), err)
+	}
 }
 
-func saveConfig() {
-	data, _ := json.MarshalIndent(config, "", "  ")
-	os.WriteFile(configFile, data, 0o644)
+func handleDailyTag(ctx *commandContext, args string) {
+	tag := normalizeTag(args)
+	err := store.UpdateSettings(ctx.GuildID, func(gs *GuildSettings) {
+		gs.DailyTag = tag
+	})
+	if err != nil {
+		ctx.storeError(ctx.cmd("tagdnia"), err)
+		return
+	}
+	if tag == "" {
+		ctx.reply("✅ Złota myśl dnia będzie losowana ze wszystkich cytatów.")
+	} else {
+		ctx.reply(fmt.Sprintf("✅ Złota myśl dnia będzie losowana z tagu #%s.", tag))
+	}
 }
 
-func messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
-	if m.Author.ID == s.State.User.ID {
+func handleChannel(ctx *commandContext, args string) {
+	channelID := selectChannel(ctx, ctx.cmd("kanal"), args, discordgo.PermissionViewChannel|discordgo.PermissionSendMessages,
+		"✅ Na ten kanał codziennie o 9:00 będzie trafiać złota myśl dnia.")
+	if channelID == "" {
+		return
+	}
+	err := store.UpdateSettings(ctx.GuildID, func(gs *GuildSettings) {
+		gs.ChannelID = channelID
+	})
+	if err != nil {
+		ctx.storeError(ctx.cmd("kanal"), err)
 		return
 	}
+	if channelID != ctx.ChannelID {
+		ctx.reply(fmt.Sprintf("✅ Ustawiono kanał dla codziennych myśli: <#%s>", channelID))
+	}
+}
 
-	content := strings.TrimSpace(m.Content)
-
-	if content == "!zlotamysl" || content == "!zm" {
-		sendRandomQuote(s, m.ChannelID)
-	} else if strings.HasPrefix(content, "!dodaj ") {
-		quote := strings.TrimPrefix(content, "!dodaj ")
-		config.Quotes = append(config.Quotes, quote)
-		saveConfig()
-		s.ChannelMessageSend(m.ChannelID, "✅ Dodano nową złotą myśl!")
-	} else if strings.HasPrefix(content, "!usun ") {
-		numStr := strings.TrimPrefix(content, "!usun ")
-		var num int
-		fmt.Sscanf(numStr, "%d", &num)
-		if num > 0 && num <= len(config.Quotes) {
-			config.Quotes = append(config.Quotes[:num-1], config.Quotes[num:]...)
-			saveConfig()
-			s.ChannelMessageSend(m.ChannelID, "✅ Usunięto złotą myśl!")
-		} else {
-			s.ChannelMessageSend(m.ChannelID, "❌ Nieprawidłowy numer!")
-		}
-	} else if content == "!lista" {
-		sendPaginatedList(s, m.ChannelID)
-	} else if strings.HasPrefix(content, "!kanal ") {
-		channelID := strings.TrimPrefix(content, "!kanal ")
-		config.ChannelID = channelID
-		saveConfig()
-		s.ChannelMessageSend(m.ChannelID, "✅ Ustawiono kanał dla codziennych myśli!")
-	} else if content == "!pomoc" {
-		help := `**🌟 Złote Myśli Bot - Komendy:**
-
-!zlotamysl lub !zm - Wyświetl losową złotą myśl
-!dodaj <tekst> - Dodaj nową złotą myśl
-!usun <numer> - Usuń złotą myśl (podaj numer z listy)
-!lista - Pokaż wszystkie złote myśli
-!kanal <ID> - Ustaw kanał dla codziennych myśli o 9:00
-!gem - Wygeneruj wykres ETF jako PNG
-!gemsubscribe - Zapisz się na miesięczny wykres ETF (ostatni dzień miesiąca, 10:00)
-!pomoc - Pokaż tę pomoc`
-		s.ChannelMessageSend(m.ChannelID, help)
-	} else if content == "!gem" {
-		statusMsg, statusErr := s.ChannelMessageSend(m.ChannelID, "⏳ Generuję wykres...")
-		if err := generateAndSendGem(s, m.ChannelID); err != nil {
-			log.Println("!gem error:", err)
-			if statusErr == nil && statusMsg != nil {
-				s.ChannelMessageDelete(m.ChannelID, statusMsg.ID)
-			}
-			s.ChannelMessageSend(m.ChannelID, "❌ Nie udało się wygenerować wykresu")
+// handleGem obsługuje !gem [ticker]; bez tickera rysuje wszystkie gemTickers.
+func handleGem(ctx *commandContext, args string) {
+	tickers := gemTickers
+	if args != "" {
+		ticker, ok := findGemTicker(args)
+		if !ok {
+			ctx.reply(fmt.Sprintf("❌ Nie znam tickera %s. Dostępne: %s", args, strings.Join(gemTickers, ", ")))
 			return
 		}
-		if statusErr == nil && statusMsg != nil {
-			s.ChannelMessageDelete(m.ChannelID, statusMsg.ID)
-		}
-	} else if content == "!gemsubscribe" {
-		added := addGemSubscriber(m.Author.ID)
-		config.GemChannelID = m.ChannelID
-		saveConfig()
-		if added {
-			s.ChannelMessageSend(m.ChannelID, "✅ Zapisano na miesięczny wykres ETF. Ostatni dzień miesiąca o 10:00 wrzucę wykres i oznaczę zapisanych.")
-		} else {
-			s.ChannelMessageSend(m.ChannelID, "✅ Już jesteś zapisany. Ostatni dzień miesiąca o 10:00 wrzucę wykres i oznaczę zapisanych.")
-		}
-	} else if content == "!pogoda" {
-		msg := buildTomorrowWeatherMessage()
-		if msg == "" {
-			s.ChannelMessageSend(m.ChannelID, "❌ Nie udało się pobrać prognozy")
+		tickers = []string{ticker}
+	}
+	// Komenda ukośnikowa ma już "bot myśli...", więc status wysyłamy tylko dla !gem.
+	var statusMsg *discordgo.Message
+	if !ctx.slash() {
+		statusMsg, _ = ctx.reply("⏳ Generuję wykres...")
+	}
+	err := generateAndSendGem(plainText(ctx.GuildID), tickers, ctx.send)
+	if statusMsg != nil {
+		ctx.s.ChannelMessageDelete(ctx.ChannelID, statusMsg.ID)
+	}
+	if err != nil {
+		log.Println("!gem error:", err)
+		ctx.reply("❌ Nie udało się wygenerować wykresu")
+	}
+}
+
+func handleGemSubscribe(ctx *commandContext, args string) {
+	settings, err := store.Settings(ctx.GuildID)
+	if err != nil {
+		ctx.storeError(ctx.cmd("gemsubscribe"), err)
+		return
+	}
+	// Kanał sprawdzamy i testujemy tylko przy zmianie, nie przy każdym zapisie.
+	channelID, ok := parseChannelArg(args, ctx.ChannelID)
+	if !ok || channelID != settings.GemChannelID {
+		channelID = selectChannel(ctx, ctx.cmd("gemsubscribe"), args,
+			discordgo.PermissionViewChannel|discordgo.PermissionSendMessages|discordgo.PermissionAttachFiles,
+			"📈 Tutaj ostatniego dnia miesiąca o 10:00 pojawi się wykres ETF, a codziennie o 19:00 prognoza pogody.")
+		if channelID == "" {
 			return
 		}
-		s.ChannelMessageSend(m.ChannelID, msg)
+	}
+	added, err := store.AddGemSubscriber(ctx.GuildID, ctx.UserID)
+	if err == nil {
+		err = store.UpdateSettings(ctx.GuildID, func(gs *GuildSettings) {
+			gs.GemChannelID = channelID
+		})
+	}
+	if err != nil {
+		ctx.storeError(ctx.cmd("gemsubscribe"), err)
+		return
+	}
+	if added {
+		ctx.reply("✅ Zapisano na miesięczny wykres ETF. Ostatni dzień miesiąca o 10:00 wrzucę wykres i oznaczę zapisanych.")
+	} else {
+		ctx.reply("✅ Już jesteś zapisany. Ostatni dzień miesiąca o 10:00 wrzucę wykres i oznaczę zapisanych.")
 	}
 }
 
-func addGemSubscriber(userID string) bool {
-	for _, id := range config.GemSubscribers {
-		if id == userID {
-			return false
-		}
+func handleWeather(ctx *commandContext) {
+	report, err := fetchTomorrowWeather()
+	if err != nil {
+		log.Println("weather error:", err)
+		ctx.reply("❌ Nie udało się pobrać prognozy")
+		return
 	}
-	config.GemSubscribers = append(config.GemSubscribers, userID)
-	return true
+	ctx.send(report.message(plainText(ctx.GuildID), ""))
+}
+
+// storeErrorMessage loguje błąd magazynu i zwraca komunikat dla użytkownika.
+func storeErrorMessage(command string, err error) string {
+	log.Printf("%s error: %v", command, err)
+	return fmt.Sprintf("❌ Nie udało się wykonać %s - błąd danych bota. Spróbuj ponownie za chwilę.", command)
 }
 
 func isLastDayOfMonth(t time.Time) bool {
@@ -179,12 +220,12 @@ func isLastDayOfMonth(t time.Time) bool {
 	return nextDay.Month() != t.Month()
 }
 
-func mentionGemSubscribers() string {
-	if len(config.GemSubscribers) == 0 {
+func mentionGemSubscribers(subscribers []string) string {
+	if len(subscribers) == 0 {
 		return ""
 	}
 	var b strings.Builder
-	for i, id := range config.GemSubscribers {
+	for i, id := range subscribers {
 		if i > 0 {
 			b.WriteString(" ")
 		}
@@ -195,11 +236,15 @@ func mentionGemSubscribers() string {
 	return b.String()
 }
 
-func generateAndSendGem(s *discordgo.Session, channelID string) error {
+// generateAndSendGem generuje wykres GEM i przekazuje go do send - na kanał
+// z crona albo jako odpowiedź na komendę. Bez plain wykres jest obrazkiem
+// embeda ze stopami zwrotu w polach.
+func generateAndSendGem(plain bool, tickers []string, send func(*discordgo.MessageSend) (*discordgo.Message, error)) error {
 	tmpDir := os.TempDir()
 	outputPath := filepath.Join(tmpDir, fmt.Sprintf("gem_%d.png", time.Now().UnixNano()))
 
-	if err := generateGemChart(outputPath); err != nil {
+	summary, err := generateGemChart(outputPath, tickers)
+	if err != nil {
 		return err
 	}
 
@@ -211,17 +256,74 @@ func generateAndSendGem(s *discordgo.Session, channelID string) error {
 	}
 	defer file.Close()
 
-	_, err = s.ChannelFileSend(channelID, "etfs_rok.png", file)
+	msg := &discordgo.MessageSend{
+		Files: []*discordgo.File{{Name: "etfs_rok.png", ContentType: "image/png", Reader: file}},
+	}
+	if !plain {
+		msg.Embeds = []*discordgo.MessageEmbed{gemEmbed(summary, "attachment://etfs_rok.png")}
+	}
+	_, err = send(msg)
 	return err
 }
 
-func sendRandomQuote(s *discordgo.Session, channelID string) {
-	if len(config.Quotes) == 0 {
-		s.ChannelMessageSend(channelID, "Brak złotych myśli! Dodaj je komendą !dodaj")
+func gemEmbed(summary gemSummary, imageURL string) *discordgo.MessageEmbed {
+	title := "📈 Porównanie ETF - 1 rok"
+	if len(summary.Returns) == 1 {
+		title = "📈 " + summary.Returns[0].Ticker + " - 1 rok"
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:     title,
+		Color:     gemColor,
+		Author:    &discordgo.MessageEmbedAuthor{Name: "Yahoo Finance", URL: "https://finance.yahoo.com"},
+		Image:     &discordgo.MessageEmbedImage{URL: imageURL},
+		Footer:    &discordgo.MessageEmbedFooter{Text: "Dane z " + summary.DataTime.Format("02.01.2006 15:04")},
+		Timestamp: summary.DataTime.Format(time.RFC3339),
+	}
+	for _, r := range summary.Returns {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   r.Ticker,
+			Value:  fmt.Sprintf("%+.2f%%", r.Return),
+			Inline: true,
+		})
+	}
+	return embed
+}
+
+func sendRandomQuote(ctx *commandContext, tag string) {
+	quotes, err := store.Quotes(ctx.GuildID)
+	if err != nil {
+		ctx.storeError(ctx.cmd("zlotamysl"), err)
+		return
+	}
+	quotes = filterByTag(quotes, tag)
+	if len(quotes) == 0 {
+		if tag != "" {
+			ctx.reply(fmt.Sprintf("Brak złotych myśli z tagiem #%s!", normalizeTag(tag)))
+			return
+		}
+		ctx.reply("Brak złotych myśli! Dodaj je komendą " + ctx.cmd("dodaj"))
+		return
+	}
+	settings, err := store.Settings(ctx.GuildID)
+	if err != nil {
+		ctx.storeError(ctx.cmd("zlotamysl"), err)
 		return
 	}
-	quote := config.Quotes[rand.Intn(len(config.Quotes))]
-	s.ChannelMessageSend(channelID, fmt.Sprintf("✨ **Złota Myśl:** ✨\n\n*%s*", quote))
+	quote := quotes[rand.Intn(len(quotes))]
+	if settings.WeightedRandom {
+		scores, err := store.Scores(ctx.GuildID)
+		if err != nil {
+			ctx.storeError(ctx.cmd("zlotamysl"), err)
+			return
+		}
+		quote = pickWeighted(quotes, scores)
+	}
+	msg, err := ctx.send(quoteMessage(settings.PlainText, "✨ **Złota Myśl:** ✨", "✨ Złota myśl", quoteColor, quote))
+	if err != nil {
+		log.Println("quote send error:", err)
+		return
+	}
+	trackQuotePost(ctx.s, ctx.GuildID, msg, quote.ID)
 }
 
 func startCronScheduler(s *discordgo.Session) {
@@ -234,10 +336,13 @@ func startCronScheduler(s *discordgo.Session) {
 
 	_, err = c.AddFunc("0 9 * * ?", func() {
 		fmt.Println("🕐 CRON 9:00 CET!")
-		if config.ChannelID != "" {
-			// ZMIENIONO: "Złota myśl dnia" zamiast zwykłej złotej myśli
-			sendDailyQuote(s, config.ChannelID)
-		}
+		today := time.Now().In(loc).Format(dailyDateLayout)
+		forEachGuild(func(guildID string, gs GuildSettings) {
+			if gs.ChannelID != "" {
+				// ZMIENIONO: "Złota myśl dnia" zamiast zwykłej złotej myśli
+				sendDailyQuote(s, gs.ChannelID, guildID, gs.DailyTag, today, gs.PlainText)
+			}
+		})
 	})
 	if err != nil {
 		log.Fatal("Cron AddFunc błąd:", err)
@@ -248,97 +353,173 @@ func startCronScheduler(s *discordgo.Session) {
 		if !isLastDayOfMonth(now) {
 			return
 		}
-		if config.GemChannelID == "" || len(config.GemSubscribers) == 0 {
-			return
-		}
-		if msg := mentionGemSubscribers(); msg != "" {
-			s.ChannelMessageSend(config.GemChannelID, msg)
-		}
-		if err := generateAndSendGem(s, config.GemChannelID); err != nil {
-			log.Println("scheduled gem error:", err)
-			s.ChannelMessageSend(config.GemChannelID, "❌ Nie udało się wygenerować wykresu")
-		}
+		forEachGemGuild(func(gs GuildSettings, subscribers []string) {
+			if msg := mentionGemSubscribers(subscribers); msg != "" {
+				s.ChannelMessageSend(gs.GemChannelID, msg)
+			}
+			sendToChannel := func(msg *discordgo.MessageSend) (*discordgo.Message, error) {
+				return s.ChannelMessageSendComplex(gs.GemChannelID, msg)
+			}
+			if err := generateAndSendGem(gs.PlainText, gemTickers, sendToChannel); err != nil {
+				log.Println("scheduled gem error:", err)
+				s.ChannelMessageSend(gs.GemChannelID, "❌ Nie udało się wygenerować wykresu")
+			}
+		})
 	})
 	if err != nil {
 		log.Fatal("Cron AddFunc błąd:", err)
 	}
 
 	_, err = c.AddFunc("0 19 * * *", func() {
-		if config.GemChannelID == "" || len(config.GemSubscribers) == 0 {
-			return
-		}
-		msg := buildTomorrowWeatherMessage()
-		if msg == "" {
-			return
-		}
-		mention := mentionGemSubscribers()
-		if mention != "" {
-			msg = mention + "\n" + msg
-		}
-		s.ChannelMessageSend(config.GemChannelID, msg)
+		var report *weatherReport
+		forEachGemGuild(func(gs GuildSettings, subscribers []string) {
+			if report == nil {
+				r, err := fetchTomorrowWeather()
+				if err != nil {
+					log.Println("scheduled weather error:", err)
+					return
+				}
+				report = &r
+			}
+			// Tu pingi są zamierzone - subskrybenci mają dostać powiadomienie.
+			msg := report.message(gs.PlainText, mentionGemSubscribers(subscribers))
+			msg.AllowedMentions = &discordgo.MessageAllowedMentions{Parse: []discordgo.AllowedMentionType{discordgo.AllowedMentionTypeUsers}}
+			s.ChannelMessageSendComplex(gs.GemChannelID, msg)
+		})
 	})
 	if err != nil {
 		log.Fatal("Cron AddFunc błąd:", err)
 	}
 
+	// Kosz sprzątamy w nocy, poza godzinami zaplanowanych postów.
+	_, err = c.AddFunc("0 4 * * *", purgeTrash)
+	if err != nil {
+		log.Fatal("Cron AddFunc błąd:", err)
+	}
+
 	fmt.Println("✅ Cron działa - 9:00 CET codziennie!")
 	c.Start()
 }
 
-// NOWA FUNKCJA dla zaplanowanej złotej myśli dnia
-func sendDailyQuote(s *discordgo.Session, channelID string) {
-	if len(config.Quotes) == 0 {
-		s.ChannelMessageSend(channelID, "Brak złotych myśli! Dodaj je komendą !dodaj")
+// forEachGuild wywołuje fn dla każdego serwera, który ma zapisany stan.
+func forEachGuild(fn func(guildID string, gs GuildSettings)) {
+	guilds, err := store.Guilds()
+	if err != nil {
+		log.Println("guilds error:", err)
 		return
 	}
-	quote := config.Quotes[rand.Intn(len(config.Quotes))]
-	s.ChannelMessageSend(channelID, fmt.Sprintf("🌅 **Złota myśl dnia** 🌅\n\n*%s*", quote))
+	for _, guildID := range guilds {
+		gs, err := store.Settings(guildID)
+		if err != nil {
+			log.Println("guild settings error:", err)
+			continue
+		}
+		fn(guildID, gs)
+	}
+}
+
+// forEachGemGuild wywołuje fn dla serwerów z kanałem GEM i co najmniej jednym subskrybentem.
+func forEachGemGuild(fn func(gs GuildSettings, subscribers []string)) {
+	forEachGuild(func(guildID string, gs GuildSettings) {
+		if gs.GemChannelID == "" {
+			return
+		}
+		subscribers, err := store.GemSubscribers(guildID)
+		if err != nil {
+			log.Println("gem subscribers error:", err)
+			return
+		}
+		if len(subscribers) == 0 {
+			return
+		}
+		fn(gs, subscribers)
+	})
 }
 
-func sendPaginatedList(s *discordgo.Session, channelID string) {
-	if len(config.Quotes) == 0 {
-		s.ChannelMessageSend(channelID, "Brak złotych myśli!")
+// NOWA FUNKCJA dla zaplanowanej złotej myśli dnia
+func sendDailyQuote(s *discordgo.Session, channelID, guildID, tag, date string, plain bool) {
+	quotes, err := store.Quotes(guildID)
+	if err != nil {
+		log.Println("daily quotes error:", err)
 		return
 	}
+	// Pusty tag dnia nie powinien uciszyć porannego posta - wtedy losujemy ze wszystkich.
+	if tagged := filterByTag(quotes, tag); len(tagged) > 0 {
+		quotes = tagged
+	} else if tag != "" {
+		log.Printf("daily quote: brak cytatów z tagiem #%s na serwerze %s", tag, guildID)
+	}
+	if len(quotes) == 0 {
+		s.ChannelMessageSend(channelID, "Brak złotych myśli! Dodaj je komendą "+commandPrefix(guildID)+"dodaj")
+		return
+	}
+	quote, err := nextDailyQuote(guildID, quotes)
+	if err != nil {
+		log.Println("daily rotation error:", err)
+		return
+	}
+	daily := quoteMessage(plain, "🌅 **Złota myśl dnia** 🌅", "🌅 Złota myśl dnia", dailyColor, quote)
+	daily.AllowedMentions = &discordgo.MessageAllowedMentions{}
+	msg, err := s.ChannelMessageSendComplex(channelID, daily)
+	if err != nil {
+		log.Println("daily quote send error:", err)
+		return
+	}
+	recordDaily(guildID, date, msg, quote.ID)
+	trackQuotePost(s, guildID, msg, quote.ID)
+}
 
-	const maxChars = 1800
-	const maxQuotesPerPage = 12
-
-	for i := 0; i < len(config.Quotes); i += maxQuotesPerPage {
-		end := i + maxQuotesPerPage
-		if end > len(config.Quotes) {
-			end = len(config.Quotes)
+func sendPaginatedList(ctx *commandContext, tag string) {
+	quotes, err := store.Quotes(ctx.GuildID)
+	if err != nil {
+		ctx.storeError(ctx.cmd("lista"), err)
+		return
+	}
+	quotes = filterByTag(quotes, tag)
+	if len(quotes) == 0 {
+		if tag != "" {
+			ctx.reply(fmt.Sprintf("Brak złotych myśli z tagiem #%s!", normalizeTag(tag)))
+			return
 		}
+		ctx.reply("Brak złotych myśli!")
+		return
+	}
 
-		var msg strings.Builder
-		msg.WriteString(fmt.Sprintf("**📜 Złote Myśli (%d-%d/%d):**\n\n", i+1, end, len(config.Quotes)))
-
-		pageChars := 50
-		for j := i; j < end; j++ {
-			quoteNum := fmt.Sprintf("%d. ", j+1)
-			quotePreview := config.Quotes[j]
-
-			if len(quotePreview) > 100 {
-				quotePreview = quotePreview[:97] + "..."
-			}
-
-			line := quoteNum + quotePreview + "\n"
-			if pageChars+len(line) > maxChars {
-				break
-			}
-
-			msg.WriteString(line)
-			pageChars += len(line)
-		}
+	title := "Złote Myśli"
+	if tag != "" {
+		title += " #" + normalizeTag(tag)
+	}
+	lines := make([]string, len(quotes))
+	for i, q := range quotes {
+		lines[i] = fmt.Sprintf("%d. %s", q.ID, truncateRunes(q.Text, 100))
+	}
+	sendPages(ctx, title, lines)
+}
 
-		// POPRAWIONE: _ dla message, err dla błędu
-		if _, err := s.ChannelMessageSend(channelID, msg.String()); err != nil {
-			log.Println("Błąd wysyłania listy:", err)
-			return
+func sendSearchResults(ctx *commandContext, query string) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		ctx.reply("❌ Podaj frazę do wyszukania, np. " + ctx.cmd("szukaj") + " wytrwałość")
+		return
+	}
+	quotes, err := store.Quotes(ctx.GuildID)
+	if err != nil {
+		ctx.storeError(ctx.cmd("szukaj"), err)
+		return
+	}
+	var lines []string
+	for _, q := range quotes {
+		if matchesSearch(q, terms) {
+			lines = append(lines, searchResultLine(q.ID, q, terms))
 		}
-
-		time.Sleep(1000 * time.Millisecond)
 	}
+	// Długie zapytanie w tytule wypchnęłoby stronę ponad limit wiadomości.
+	shown := truncateRunes(query, 100)
+	if len(lines) == 0 {
+		ctx.reply(fmt.Sprintf("🔍 Nic nie znaleziono dla „%s”.", shown))
+		return
+	}
+	sendPages(ctx, fmt.Sprintf("Wyniki dla „%s”", shown), lines)
 }
 
 type weatherResponse struct {
@@ -357,23 +538,70 @@ type forecast struct {
 	Date string
 }
 
-func buildTomorrowWeatherMessage() string {
-	lesna, err := fetchTomorrowForecast(51.0156, 15.2634)
-	if err != nil {
-		log.Println("weather Lesna error:", err)
-		return ""
-	}
-	bielsko, err := fetchTomorrowForecast(49.8224, 19.0469)
-	if err != nil {
-		log.Println("weather Bielsko error:", err)
-		return ""
+// weatherCities to miejsca z prognozy na jutro.
+var weatherCities = []struct {
+	name     string
+	lat, lon float64
+}{
+	{"Leśna", 51.0156, 15.2634},
+	{"Bielsko-Biała", 49.8224, 19.0469},
+}
+
+type cityForecast struct {
+	Name string
+	forecast
+}
+
+// weatherReport to prognoza na jutro dla weatherCities.
+type weatherReport struct {
+	Cities    []cityForecast
+	FetchedAt time.Time
+}
+
+func fetchTomorrowWeather() (weatherReport, error) {
+	report := weatherReport{FetchedAt: time.Now()}
+	for _, city := range weatherCities {
+		fc, err := fetchTomorrowForecast(city.lat, city.lon)
+		if err != nil {
+			return weatherReport{}, fmt.Errorf("%s: %w", city.name, err)
+		}
+		report.Cities = append(report.Cities, cityForecast{Name: city.name, forecast: fc})
 	}
+	return report, nil
+}
 
-	var b strings.Builder
-	b.WriteString("🌤️ **Pogoda na jutro**\n")
-	b.WriteString(fmt.Sprintf("Leśna: %s, %.0f/%.0f°C\n", weatherDescription(lesna.Code), lesna.MinC, lesna.MaxC))
-	b.WriteString(fmt.Sprintf("Bielsko-Biała: %s, %.0f/%.0f°C", weatherDescription(bielsko.Code), bielsko.MinC, bielsko.MaxC))
-	return b.String()
+// message buduje wiadomość z prognozą; prefix (np. pingi subskrybentów)
+// trafia przed prognozę.
+func (r weatherReport) message(plain bool, prefix string) *discordgo.MessageSend {
+	if plain {
+		var b strings.Builder
+		if prefix != "" {
+			b.WriteString(prefix + "\n")
+		}
+		b.WriteString("🌤️ **Pogoda na jutro**")
+		for _, c := range r.Cities {
+			b.WriteString(fmt.Sprintf("\n%s: %s, %.0f/%.0f°C", c.Name, weatherDescription(c.Code), c.MinC, c.MaxC))
+		}
+		return &discordgo.MessageSend{Content: b.String()}
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:     "🌤️ Pogoda na jutro",
+		Color:     weatherColor,
+		Author:    &discordgo.MessageEmbedAuthor{Name: "Open-Meteo", URL: "https://open-meteo.com"},
+		Footer:    &discordgo.MessageEmbedFooter{Text: "Prognoza pobrana"},
+		Timestamp: r.FetchedAt.Format(time.RFC3339),
+	}
+	for _, c := range r.Cities {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   c.Name,
+			Value:  fmt.Sprintf("%s\n%.0f/%.0f°C", weatherDescription(c.Code), c.MinC, c.MaxC),
+			Inline: true,
+		})
+	}
+	if len(r.Cities) > 0 {
+		embed.Description = "Prognoza na " + r.Cities[0].Date
+	}
+	return &discordgo.MessageSend{Content: prefix, Embeds: []*discordgo.MessageEmbed{embed}}
 }
 
 func fetchTomorrowForecast(lat, lon float64) (forecast, error) {
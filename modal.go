@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Limity pól okna /dodaj.
+const (
+	minQuoteRunes  = 3
+	maxQuoteRunes  = 1000
+	maxAuthorRunes = 100
+	maxSourceRunes = 200
+	maxQuoteTags   = 10
+)
+
+// draftTimeout to czas, przez który podgląd z okna /dodaj czeka na decyzję.
+const draftTimeout = 15 * time.Minute
+
+// addDraft to złota myśl z okna /dodaj, która czeka w podglądzie na zapis.
+type addDraft struct {
+	userID  string
+	guildID string
+	quote   Quote
+	// tags to pole tagów tak, jak je wpisano, do ponownego otwarcia okna.
+	tags string
+	// force to opcja force z /dodaj - zapis pomija sprawdzanie powtórek.
+	force bool
+	// expiry usuwa szkic po draftTimeout od ostatniej zmiany.
+	expiry *time.Timer
+}
+
+var (
+	draftMu     sync.Mutex
+	drafts      = map[int]*addDraft{}
+	nextDraftID int
+)
+
+// openAddModal otwiera okno z polami złotej myśli, wypełnione treścią draft.
+func openAddModal(s *discordgo.Session, i *discordgo.InteractionCreate, draftID int, draft *addDraft) {
+	field := func(id, label string, style discordgo.TextInputStyle, value string, required bool, max int, placeholder string) discordgo.MessageComponent {
+		return discordgo.ActionsRow{Components: []discordgo.MessageComponent{discordgo.TextInput{
+			CustomID:    id,
+			Label:       label,
+			Style:       style,
+			Value:       value,
+			Required:    required,
+			MaxLength:   max,
+			Placeholder: placeholder,
+		}}}
+	}
+	// Okno nie ma pola na force, więc opcja jedzie w CustomID.
+	modalID := "add:modal:" + sessionKey(draftID)
+	if draft.force {
+		modalID += ":force"
+	}
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: modalID,
+			Title:    "Nowa złota myśl",
+			Components: []discordgo.MessageComponent{
+				field("tekst", "Treść", discordgo.TextInputParagraph, draft.quote.Text, true, maxQuoteRunes, "Może mieć wiele linii"),
+				field("autor", "Autor", discordgo.TextInputShort, draft.quote.Author, false, maxAuthorRunes, "Kto to powiedział"),
+				field("zrodlo", "Źródło", discordgo.TextInputShort, draft.quote.Source, false, maxSourceRunes, "Książka, film, link..."),
+				field("tagi", "Tagi", discordgo.TextInputShort, draft.tags, false, 200, "praca motywacja"),
+			},
+		},
+	})
+	if err != nil {
+		log.Println("add modal error:", err)
+	}
+}
+
+// validateQuote sprawdza długości pól i zwraca listę problemów.
+func validateQuote(q Quote) []string {
+	var problems []string
+	if n := utf8.RuneCountInString(q.Text); n < minQuoteRunes {
+		problems = append(problems, fmt.Sprintf("treść musi mieć co najmniej %d znaki", minQuoteRunes))
+	} else if n > maxQuoteRunes {
+		problems = append(problems, fmt.Sprintf("treść może mieć najwyżej %d znaków (ma %d)", maxQuoteRunes, n))
+	}
+	if n := utf8.RuneCountInString(q.Author); n > maxAuthorRunes {
+		problems = append(problems, fmt.Sprintf("autor może mieć najwyżej %d znaków", maxAuthorRunes))
+	}
+	if n := utf8.RuneCountInString(q.Source); n > maxSourceRunes {
+		problems = append(problems, fmt.Sprintf("źródło może mieć najwyżej %d znaków", maxSourceRunes))
+	}
+	if len(q.Tags) > maxQuoteTags {
+		problems = append(problems, fmt.Sprintf("najwyżej %d tagów", maxQuoteTags))
+	}
+	return problems
+}
+
+// handleAddModal przyjmuje wypełnione okno i pokazuje podgląd (tylko
+// wysyłającemu) z przyciskami zapisu, poprawki i anulowania.
+func handleAddModal(s *discordgo.Session, i *discordgo.InteractionCreate, args string) {
+	values := map[string]string{}
+	for _, row := range i.ModalSubmitData().Components {
+		if r, ok := row.(*discordgo.ActionsRow); ok {
+			for _, c := range r.Components {
+				if input, ok := c.(*discordgo.TextInput); ok {
+					values[input.CustomID] = strings.TrimSpace(input.Value)
+				}
+			}
+		}
+	}
+	_, rest, _ := strings.Cut(args, ":")
+	key, flag, _ := strings.Cut(rest, ":")
+	draft := &addDraft{userID: interactionUser(i), guildID: i.GuildID, tags: values["tagi"], force: flag == "force"}
+	draft.quote = Quote{Text: values["tekst"], Author: values["autor"], Source: values["zrodlo"]}
+	draft.quote.Tags, _ = parseTags(tagsAsHashes(strings.ReplaceAll(draft.tags, ",", " ")))
+
+	// Okno otwarte z "Popraw" nadpisuje swój szkic i odświeża ten sam podgląd.
+	id, _ := parseSessionKey(key)
+	draftMu.Lock()
+	if old, ok := drafts[id]; ok && old.userID == draft.userID {
+		old.expiry.Stop()
+	} else {
+		nextDraftID++
+		id = nextDraftID
+	}
+	draft.expiry = time.AfterFunc(draftTimeout, func() { expireDraft(id, draft) })
+	drafts[id] = draft
+	draftMu.Unlock()
+
+	responseType := discordgo.InteractionResponseChannelMessageWithSource
+	if i.Message != nil {
+		responseType = discordgo.InteractionResponseUpdateMessage
+	}
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: responseType,
+		Data: draftPreview(id, draft, ""),
+	})
+	if err != nil {
+		log.Println("add preview error:", err)
+	}
+}
+
+// draftPreview buduje podgląd szkicu; note (np. wynik nieudanego zapisu)
+// trafia nad podgląd.
+func draftPreview(id int, draft *addDraft, note string) *discordgo.InteractionResponseData {
+	preview := draft.quote
+	preview.SubmitterID = draft.userID
+	content := "👀 **Podgląd** - tak będzie wyglądać Twoja złota myśl."
+	buttons := []discordgo.MessageComponent{
+		discordgo.Button{Label: "Zapisz", Style: discordgo.SuccessButton, Emoji: &discordgo.ComponentEmoji{Name: "✅"}, CustomID: "add:save:" + sessionKey(id)},
+	}
+	if problems := validateQuote(draft.quote); len(problems) > 0 {
+		content = "❌ Popraw, zanim zapiszesz: " + strings.Join(problems, "; ") + "."
+		buttons = nil
+	} else if note != "" {
+		content = note
+		buttons = []discordgo.MessageComponent{
+			discordgo.Button{Label: "Zapisz mimo to", Style: discordgo.DangerButton, CustomID: "add:force:" + sessionKey(id)},
+		}
+	}
+	buttons = append(buttons,
+		discordgo.Button{Label: "Popraw", Style: discordgo.SecondaryButton, Emoji: &discordgo.ComponentEmoji{Name: "✏️"}, CustomID: "add:edit:" + sessionKey(id)},
+		discordgo.Button{Label: "Anuluj", Style: discordgo.SecondaryButton, CustomID: "add:cancel:" + sessionKey(id)},
+	)
+	data := &discordgo.InteractionResponseData{
+		Content:         content,
+		Embeds:          []*discordgo.MessageEmbed{},
+		Components:      []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}},
+		Flags:           discordgo.MessageFlagsEphemeral,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	}
+	if preview.Text != "" {
+		embed := quoteEmbed("✨ Złota myśl", quoteColor, preview)
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: "Podgląd - jeszcze niezapisane"}
+		data.Embeds = []*discordgo.MessageEmbed{embed}
+	}
+	return data
+}
+
+func dropDraft(id int) {
+	draftMu.Lock()
+	if draft, ok := drafts[id]; ok {
+		draft.expiry.Stop()
+		delete(drafts, id)
+	}
+	draftMu.Unlock()
+}
+
+// expireDraft usuwa szkic po czasie, chyba że w międzyczasie go zastąpiono.
+func expireDraft(id int, draft *addDraft) {
+	draftMu.Lock()
+	if drafts[id] == draft {
+		delete(drafts, id)
+	}
+	draftMu.Unlock()
+}
+
+// handleAddButton obsługuje przyciski podglądu "add:<akcja>:<klucz szkicu>".
+func handleAddButton(s *discordgo.Session, i *discordgo.InteractionCreate, args string) {
+	action, key, _ := strings.Cut(args, ":")
+	id, ok := parseSessionKey(key)
+	draftMu.Lock()
+	draft := drafts[id]
+	draftMu.Unlock()
+	if !ok || draft == nil || draft.userID != interactionUser(i) {
+		replaceInteractionMessage(s, i, "⌛ Ten podgląd wygasł - użyj /dodaj jeszcze raz.")
+		return
+	}
+
+	switch action {
+	case "edit":
+		openAddModal(s, i, id, draft)
+	case "cancel":
+		dropDraft(id)
+		replaceInteractionMessage(s, i, "✖️ Anulowano, nic nie zostało zapisane.")
+	case "save", "force":
+		if len(validateQuote(draft.quote)) > 0 {
+			return
+		}
+		// Szkic wyjmujemy przed zapisem, żeby podwójne kliknięcie nie zapisało
+		// myśli dwa razy; przy nieudanym zapisie wraca na miejsce.
+		draftMu.Lock()
+		taken := drafts[id] == draft
+		if taken {
+			delete(drafts, id)
+		}
+		draftMu.Unlock()
+		if !taken {
+			replaceInteractionMessage(s, i, "⌛ Ten podgląd jest już nieaktualny.")
+			return
+		}
+		reply, ok := submitQuote(s, draft.guildID, draft.userID, "/dodaj", draft.quote, action == "force" || draft.force)
+		if !ok {
+			draftMu.Lock()
+			if _, exists := drafts[id]; !exists {
+				drafts[id] = draft
+				// Licznik mógł w tym czasie wygasnąć na pustym miejscu.
+				draft.expiry.Reset(draftTimeout)
+			}
+			draftMu.Unlock()
+			err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseUpdateMessage,
+				Data: draftPreview(id, draft, reply),
+			})
+			if err != nil {
+				log.Println("add preview error:", err)
+			}
+			return
+		}
+		draft.expiry.Stop()
+		replaceInteractionMessage(s, i, reply)
+	}
+}
+
+// replaceInteractionMessage podmienia treść wiadomości z przyciskami i zdejmuje
+// z niej przyciski oraz podgląd.
+func replaceInteractionMessage(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:         content,
+			Components:      []discordgo.MessageComponent{},
+			Embeds:          []*discordgo.MessageEmbed{},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+	if err != nil {
+		log.Println("interaction update error:", err)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// submitForModeration odkłada cytat do kolejki i ogłasza go na kanale
+// moderatorów z przyciskami akceptacji i odrzucenia.
+func submitForModeration(s *discordgo.Session, guildID, command, modChannelID string, quote Quote) (string, bool) {
+	pending, err := store.AddPending(guildID, quote)
+	if err != nil {
+		return storeErrorMessage(command, err), false
+	}
+	_, err = s.ChannelMessageSendComplex(modChannelID, &discordgo.MessageSend{
+		Content:         formatQuote(fmt.Sprintf("📨 **Zgłoszenie #%d** czeka na akceptację:", pending.ID), pending),
+		Components:      moderationButtons(pending.ID),
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		log.Println("moderation announce error:", err)
+		return fmt.Sprintf("📨 Zgłoszenie #%d trafiło do kolejki, ale nie udało się powiadomić moderatorów. Sprawdź uprawnienia bota na kanale moderacji.", pending.ID), true
+	}
+	return fmt.Sprintf("📨 Dzięki! Zgłoszenie #%d czeka na akceptację moderatorów.", pending.ID), true
+}
+
+func moderationButtons(pendingID int) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "Akceptuj",
+				Style:    discordgo.SuccessButton,
+				Emoji:    &discordgo.ComponentEmoji{Name: "✅"},
+				CustomID: fmt.Sprintf("mod:approve:%d", pendingID),
+			},
+			discordgo.Button{
+				Label:    "Odrzuć",
+				Style:    discordgo.DangerButton,
+				Emoji:    &discordgo.ComponentEmoji{Name: "🗑️"},
+				CustomID: fmt.Sprintf("mod:reject:%d", pendingID),
+			},
+		}},
+	}
+}
+
+// handleModerationButton rozpatruje zgłoszenie; kto może klikać, ustala
+// reguła dostępu "zatwierdz".
+func handleModerationButton(s *discordgo.Session, i *discordgo.InteractionCreate, args string) {
+	action, idStr, _ := strings.Cut(args, ":")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return
+	}
+	if ok, msg := checkInteractionAccess(i, "zatwierdz"); !ok {
+		respondEphemeral(s, i, msg)
+		return
+	}
+	userID := interactionUser(i)
+
+	var quote Quote
+	var verdict string
+	switch action {
+	case "approve":
+		quote, err = store.ApprovePending(i.GuildID, id)
+		if err == nil {
+			if err := addToDailyBag(i.GuildID, quote.ID); err != nil {
+				log.Println("daily bag error:", err)
+			}
+			verdict = fmt.Sprintf("✅ Zaakceptowane przez <@%s> jako złota myśl #%d.", userID, quote.ID)
+		}
+	case "reject":
+		quote, err = store.RejectPending(i.GuildID, id)
+		verdict = fmt.Sprintf("🗑️ Odrzucone przez <@%s>.", userID)
+	default:
+		return
+	}
+	content := i.Message.Content
+	switch {
+	case errors.Is(err, errPendingNotFound):
+		verdict = "Zgłoszenie zostało już rozpatrzone."
+	case err != nil:
+		log.Println("moderation error:", err)
+		respondEphemeral(s, i, "❌ Nie udało się zapisać decyzji, spróbuj ponownie.")
+		return
+	default:
+		content = formatQuote(fmt.Sprintf("📨 **Zgłoszenie #%d**", id), quote)
+	}
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:         content + "\n\n" + verdict,
+			Components:      []discordgo.MessageComponent{},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+	if err != nil {
+		log.Println("moderation respond error:", err)
+	}
+}
+
+// handleModerationSetting obsługuje !moderacja [#kanał|ID|tutaj|off].
+func handleModerationSetting(ctx *commandContext, args string) {
+	switch arg := strings.ToLower(args); arg {
+	case "":
+		settings, err := store.Settings(ctx.GuildID)
+		var pending []Quote
+		if err == nil {
+			pending, err = store.Pending(ctx.GuildID)
+		}
+		if err != nil {
+			ctx.storeError(ctx.cmd("moderacja"), err)
+			return
+		}
+		if settings.ModChannelID == "" {
+			ctx.reply(fmt.Sprintf("Moderacja jest wyłączona - %s od razu dodaje złote myśli. Włącz ją komendą %s #kanał-moderatorów", ctx.cmd("dodaj"), ctx.cmd("moderacja")))
+			return
+		}
+		ctx.reply(fmt.Sprintf("🛡️ Moderacja włączona, zgłoszenia trafiają na <#%s>. Czeka: %d.", settings.ModChannelID, len(pending)))
+	case "off":
+		err := store.UpdateSettings(ctx.GuildID, func(gs *GuildSettings) {
+			gs.ModChannelID = ""
+		})
+		if err != nil {
+			ctx.storeError(ctx.cmd("moderacja"), err)
+			return
+		}
+		ctx.reply("✅ Moderacja wyłączona. Zgłoszenia, które już czekają, nadal można rozpatrzyć przyciskami.")
+	default:
+		if arg == "on" {
+			args = "tutaj"
+		}
+		channelID := selectChannel(ctx, ctx.cmd("moderacja"), args, discordgo.PermissionViewChannel|discordgo.PermissionSendMessages,
+			"🛡️ Moderacja włączona. Nowe złote myśli z "+ctx.cmd("dodaj")+" będą tutaj czekać na akceptację.")
+		if channelID == "" {
+			return
+		}
+		err := store.UpdateSettings(ctx.GuildID, func(gs *GuildSettings) {
+			gs.ModChannelID = channelID
+		})
+		if err != nil {
+			ctx.storeError(ctx.cmd("moderacja"), err)
+			return
+		}
+		if channelID != ctx.ChannelID {
+			ctx.reply(fmt.Sprintf("🛡️ Zgłoszenia trafią na <#%s>.", channelID))
+		}
+	}
+}
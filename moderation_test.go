@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// TestPendingQueue sprawdza numerację zgłoszeń i ich przejście do cytatów.
+func TestPendingQueue(t *testing.T) {
+	forEachStore(t, func(t *testing.T, st Store) {
+		a, _ := st.AddPending(testGuild, Quote{Text: "pierwsze", SubmitterID: "u1", Tags: []string{"a"}})
+		b, _ := st.AddPending(testGuild, Quote{Text: "drugie"})
+		if a.ID != 1 || b.ID != 2 {
+			t.Errorf("numery zgłoszeń = %d, %d", a.ID, b.ID)
+		}
+		if pending, _ := st.Pending(testGuild); len(pending) != 2 {
+			t.Fatalf("Pending = %+v", pending)
+		}
+		approved, err := st.ApprovePending(testGuild, 1)
+		if err != nil || approved.ID != 4 || approved.Text != "pierwsze" || !slices.Equal(approved.Tags, []string{"a"}) {
+			t.Errorf("ApprovePending = %+v, %v", approved, err)
+		}
+		if got := quoteIDs(mustQuotes(t, st)); !slices.Equal(got, []int{1, 2, 3, 4}) {
+			t.Errorf("Quotes = %v", got)
+		}
+		if _, err := st.ApprovePending(testGuild, 1); !errors.Is(err, errPendingNotFound) {
+			t.Errorf("drugie ApprovePending = %v", err)
+		}
+		if rejected, err := st.RejectPending(testGuild, 2); err != nil || rejected.Text != "drugie" {
+			t.Errorf("RejectPending = %+v, %v", rejected, err)
+		}
+		if pending, _ := st.Pending(testGuild); len(pending) != 0 {
+			t.Errorf("Pending = %+v, chcę pustej", pending)
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// pageTimeout to czas, po którym przyciski listy przestają działać i znikają.
+const pageTimeout = 10 * time.Minute
+
+// pageSession to stronicowana lista wysłana jako jedna wiadomość. Strony
+// przełącza tylko osoba, która wywołała komendę.
+type pageSession struct {
+	userID string
+	pages  []string
+}
+
+var (
+	pageMu       sync.Mutex
+	pageSessions = map[int]*pageSession{}
+	nextPageID   int
+)
+
+// splitPages dzieli linie na strony mieszczące się w jednej wiadomości.
+func splitPages(title string, lines []string) []string {
+	const maxChars = 1800
+	const maxQuotesPerPage = 12
+
+	var pages []string
+	for i := 0; i < len(lines); {
+		end := i
+		pageChars := 50
+		for end < len(lines) && end-i < maxQuotesPerPage {
+			if end > i && pageChars+len(lines[end])+1 > maxChars {
+				break
+			}
+			pageChars += len(lines[end]) + 1
+			end++
+		}
+
+		var msg strings.Builder
+		msg.WriteString(fmt.Sprintf("**📜 %s (%d-%d/%d):**\n\n", title, i+1, end, len(lines)))
+		for _, line := range lines[i:end] {
+			msg.WriteString(line)
+			msg.WriteString("\n")
+		}
+		pages = append(pages, msg.String())
+		i = end
+	}
+	return pages
+}
+
+// sendPages wysyła listę jako jedną wiadomość; dłuższa dostaje przyciski
+// ◀️/▶️, które podmieniają jej treść na inną stronę.
+func sendPages(ctx *commandContext, title string, lines []string) {
+	pages := splitPages(title, lines)
+	if len(pages) == 0 {
+		return
+	}
+	if len(pages) == 1 {
+		if _, err := ctx.reply(pages[0]); err != nil {
+			log.Println("Błąd wysyłania listy:", err)
+		}
+		return
+	}
+
+	pageMu.Lock()
+	nextPageID++
+	id := nextPageID
+	pageSessions[id] = &pageSession{userID: ctx.UserID, pages: pages}
+	pageMu.Unlock()
+
+	msg, err := ctx.send(&discordgo.MessageSend{
+		Content:    pages[0],
+		Components: pageButtons(id, 0, len(pages)),
+	})
+	if err != nil {
+		log.Println("Błąd wysyłania listy:", err)
+		dropPageSession(id)
+		return
+	}
+	time.AfterFunc(pageTimeout, func() {
+		expirePageSession(ctx.s, id, msg)
+	})
+}
+
+func pageButtons(id, page, total int) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Style:    discordgo.SecondaryButton,
+				Emoji:    &discordgo.ComponentEmoji{Name: "◀️"},
+				CustomID: fmt.Sprintf("page:%s:%d", sessionKey(id), page-1),
+				Disabled: page == 0,
+			},
+			discordgo.Button{
+				Label:    fmt.Sprintf("%d/%d", page+1, total),
+				Style:    discordgo.SecondaryButton,
+				CustomID: fmt.Sprintf("page:%s:current", sessionKey(id)),
+				Disabled: true,
+			},
+			discordgo.Button{
+				Style:    discordgo.SecondaryButton,
+				Emoji:    &discordgo.ComponentEmoji{Name: "▶️"},
+				CustomID: fmt.Sprintf("page:%s:%d", sessionKey(id), page+1),
+				Disabled: page == total-1,
+			},
+		}},
+	}
+}
+
+func dropPageSession(id int) *pageSession {
+	pageMu.Lock()
+	defer pageMu.Unlock()
+	session := pageSessions[id]
+	delete(pageSessions, id)
+	return session
+}
+
+// expirePageSession zapomina listę i zdejmuje z wiadomości martwe przyciski.
+func expirePageSession(s *discordgo.Session, id int, msg *discordgo.Message) {
+	if dropPageSession(id) == nil {
+		return
+	}
+	empty := []discordgo.MessageComponent{}
+	_, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:         msg.ID,
+		Channel:    msg.ChannelID,
+		Components: &empty,
+	})
+	if err != nil {
+		log.Println("page expire error:", err)
+	}
+}
+
+// handlePageButton obsługuje przyciski "page:<klucz listy>:<strona>".
+func handlePageButton(s *discordgo.Session, i *discordgo.InteractionCreate, args string) {
+	key, pageStr, _ := strings.Cut(args, ":")
+	page, err := strconv.Atoi(pageStr)
+	if err != nil {
+		return
+	}
+	id, ok := parseSessionKey(key)
+
+	pageMu.Lock()
+	session := pageSessions[id]
+	pageMu.Unlock()
+	if !ok || session == nil {
+		respondEphemeral(s, i, "⌛ Ta lista wygasła - wywołaj komendę jeszcze raz.")
+		return
+	}
+	if interactionUser(i) != session.userID {
+		respondEphemeral(s, i, "⛔ Tę listę przewija tylko osoba, która ją wywołała. Wywołaj komendę, żeby mieć własną.")
+		return
+	}
+	page = max(0, min(page, len(session.pages)-1))
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:         session.pages[page],
+			Components:      pageButtons(id, page, len(session.pages)),
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+	if err != nil {
+		log.Println("page respond error:", err)
+	}
+}
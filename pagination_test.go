@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitPages(t *testing.T) {
+	lines := func(n, size int) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = strings.Repeat("x", size)
+		}
+		return out
+	}
+	tests := []struct {
+		name    string
+		lines   []string
+		headers []string
+	}{
+		{"pusto", nil, nil},
+		{"jedna strona", lines(3, 10), []string{"(1-3/3)"}},
+		{"limit cytatów", lines(30, 10), []string{"(1-12/30)", "(13-24/30)", "(25-30/30)"}},
+		{"limit znaków", lines(20, 200), []string{"(1-8/20)", "(9-16/20)", "(17-20/20)"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pages := splitPages("Lista", tc.lines)
+			if len(pages) != len(tc.headers) {
+				t.Fatalf("stron: %d, chcę %d", len(pages), len(tc.headers))
+			}
+			for i, page := range pages {
+				if !strings.HasPrefix(page, "**📜 Lista "+tc.headers[i]+":**") {
+					t.Errorf("strona %d zaczyna się od %q", i, page[:min(len(page), 40)])
+				}
+				if n := utf8.RuneCountInString(page); n > 2000 {
+					t.Errorf("strona %d ma %d znaków", i, n)
+				}
+			}
+		})
+	}
+}
+
+func TestSessionKey(t *testing.T) {
+	if id, ok := parseSessionKey(sessionKey(7)); !ok || id != 7 {
+		t.Errorf("parseSessionKey(sessionKey(7)) = %d, %v", id, ok)
+	}
+	// Przyciski sprzed restartu mają inny nonce albo jeszcze sam numer.
+	for _, key := range []string{"7", "stary.7", runNonce + ".x", ""} {
+		if _, ok := parseSessionKey(key); ok {
+			t.Errorf("parseSessionKey(%q) przyjął obcy klucz", key)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	defaultPrefix   = "!"
+	maxPrefixLength = 3
+)
+
+// prefixes trzyma prefiksy serwerów w pamięci: commandPrefix woła się dla
+// każdej wiadomości, więc nie chcemy za każdym razem sięgać do magazynu.
+var (
+	prefixMu sync.Mutex
+	prefixes = map[string]string{}
+)
+
+// commandPrefix zwraca prefiks komend serwera. Przy błędzie odczytu zostaje
+// domyślny, a wzmianka bota i tak działa.
+func commandPrefix(guildID string) string {
+	prefixMu.Lock()
+	prefix, ok := prefixes[guildID]
+	prefixMu.Unlock()
+	if ok {
+		return prefix
+	}
+	settings, err := store.Settings(guildID)
+	if err != nil {
+		log.Println("prefix setting error:", err)
+		return defaultPrefix
+	}
+	prefix = cmp.Or(settings.Prefix, defaultPrefix)
+	prefixMu.Lock()
+	prefixes[guildID] = prefix
+	prefixMu.Unlock()
+	return prefix
+}
+
+// botMention zdejmuje z początku content wzmiankę bota ("<@id>" albo "<@!id>").
+func botMention(s *discordgo.Session, content string) (string, bool) {
+	for _, mention := range []string{"<@" + s.State.User.ID + ">", "<@!" + s.State.User.ID + ">"} {
+		if rest, ok := strings.CutPrefix(content, mention); ok {
+			return strings.TrimSpace(rest), true
+		}
+	}
+	return "", false
+}
+
+// parsePrefix sprawdza, czy wiadomość jest komendą - z prefiksem serwera
+// albo po wzmiance bota - i zwraca ją bez prefiksu, np. "zm praca".
+func parsePrefix(s *discordgo.Session, m *discordgo.MessageCreate, content string) (string, bool) {
+	if rest, ok := botMention(s, content); ok {
+		if rest == "" {
+			prefix := commandPrefix(m.GuildID)
+			s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("👋 Mój prefiks tutaj to %s - wpisz %spomoc albo @%s pomoc.", prefix, prefix, s.State.User.Username))
+			return "", false
+		}
+		// Po wzmiance prefiks jest opcjonalny, ale jeśli jest, to na tych samych zasadach.
+		if !strings.HasPrefix(rest, commandPrefix(m.GuildID)) {
+			return rest, true
+		}
+		return cutCommandPrefix(rest, commandPrefix(m.GuildID))
+	}
+	return cutCommandPrefix(content, commandPrefix(m.GuildID))
+}
+
+// cutCommandPrefix zdejmuje prefiks, o ile zaraz po nim stoi nazwa komendy.
+func cutCommandPrefix(content, prefix string) (string, bool) {
+	rest, ok := strings.CutPrefix(content, prefix)
+	if !ok || rest == "" || strings.HasPrefix(rest, " ") {
+		return "", false
+	}
+	return rest, true
+}
+
+// prefixProblem zwraca powód, dla którego prefix się nie nadaje, albo "".
+func prefixProblem(prefix string) string {
+	switch {
+	case strings.ContainsAny(prefix, " \t\n"):
+		return "Prefiks nie może zawierać spacji."
+	case utf8.RuneCountInString(prefix) > maxPrefixLength:
+		return fmt.Sprintf("Prefiks może mieć najwyżej %d znaki, np. ? albo $$.", maxPrefixLength)
+	case strings.HasPrefix(prefix, "<") || strings.HasPrefix(prefix, "@"):
+		return "Prefiks nie może zaczynać się od < ani @ - myliłby się ze wzmiankami."
+	}
+	return ""
+}
+
+// handlePrefix obsługuje !prefiks [znak|domyslny].
+func handlePrefix(ctx *commandContext, args string) {
+	current := ctx.Prefix
+	if args == "" {
+		ctx.reply(fmt.Sprintf("Prefiks komend na tym serwerze: %s (zmień: %sprefiks <znak>). Wzmianka bota działa zawsze.", current, current))
+		return
+	}
+	prefix := args
+	if foldText(args) == "domyslny" {
+		prefix = defaultPrefix
+	}
+	if problem := prefixProblem(prefix); problem != "" {
+		ctx.reply("❌ " + problem)
+		return
+	}
+	err := store.UpdateSettings(ctx.GuildID, func(gs *GuildSettings) {
+		gs.Prefix = ""
+		if prefix != defaultPrefix {
+			gs.Prefix = prefix
+		}
+	})
+	if err != nil {
+		ctx.storeError(ctx.cmd("prefiks"), err)
+		return
+	}
+	prefixMu.Lock()
+	prefixes[ctx.GuildID] = prefix
+	prefixMu.Unlock()
+	ctx.reply(fmt.Sprintf("✅ Nowy prefiks komend: %s - np. %szm. Wzmianka bota działa dalej.", prefix, prefix))
+}
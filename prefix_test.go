@@ -0,0 +1,106 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// TestReadsDoNotCreateGuild sprawdza, że odczyty (np. prefiksu przy każdej wiadomości) nie zapisują serwera.
+func TestReadsDoNotCreateGuild(t *testing.T) {
+	forEachStore(t, func(t *testing.T, st Store) {
+		if got := quoteIDs(mustQuotes(t, st)); !slices.Equal(got, []int{1, 2, 3}) {
+			t.Errorf("Quotes = %v, chcę domyślnych [1 2 3]", got)
+		}
+		if _, err := st.Settings(testGuild); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := st.Quote(testGuild, 2); err != nil {
+			t.Errorf("Quote(2) = %v", err)
+		}
+		if guilds, _ := st.Guilds(); len(guilds) != 0 {
+			t.Errorf("Guilds = %v po samych odczytach", guilds)
+		}
+	})
+}
+
+// usePrefix ustawia prefiks serwera w pamięci podręcznej na czas testu.
+func usePrefix(t *testing.T, guildID, prefix string) {
+	prefixMu.Lock()
+	prefixes[guildID] = prefix
+	prefixMu.Unlock()
+	t.Cleanup(func() {
+		prefixMu.Lock()
+		delete(prefixes, guildID)
+		prefixMu.Unlock()
+	})
+}
+
+func TestParsePrefix(t *testing.T) {
+	s := &discordgo.Session{State: discordgo.NewState()}
+	s.State.User = &discordgo.User{ID: "42"}
+	usePrefix(t, testGuild, "?")
+	tests := []struct {
+		content string
+		want    string
+		ok      bool
+	}{
+		{"?zm praca", "zm praca", true},
+		{"? zm", "", false},
+		{"?", "", false},
+		{"!zm", "", false},
+		{"zm", "", false},
+		{"<@42> zm", "zm", true},
+		{"<@!42> ?zm", "zm", true},
+		{"<@42> ? zm", "", false},
+		{"<@42> ?", "", false},
+		{"<@7> zm", "", false},
+	}
+	for _, tc := range tests {
+		m := &discordgo.MessageCreate{Message: &discordgo.Message{GuildID: testGuild, ChannelID: "1"}}
+		got, ok := parsePrefix(s, m, tc.content)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("parsePrefix(%q) = %q, %v, chcę %q, %v", tc.content, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	tests := []struct {
+		content, prefix, want string
+	}{
+		{"zm praca", "", "zlotamysl"},
+		{"ZM", "", "zlotamysl"},
+		{"?zm", "?", "zlotamysl"},
+		{"$$usun 3", "$$", "usun"},
+		// Po zdjęciu prefiksu "?" zostaje "!zm", a to nie jest komenda.
+		{"!zm", "", "!zm"},
+		{"!zm", "?", "!zm"},
+	}
+	for _, tc := range tests {
+		if got := commandName(tc.content, tc.prefix); got != tc.want {
+			t.Errorf("commandName(%q, %q) = %q, chcę %q", tc.content, tc.prefix, got, tc.want)
+		}
+	}
+}
+
+func TestPrefixProblem(t *testing.T) {
+	tests := []struct {
+		prefix string
+		ok     bool
+	}{
+		{"?", true},
+		{"$$", true},
+		{"zł!", true},
+		{"!!!!", false},
+		{"a b", false},
+		{"<", false},
+		{"@b", false},
+	}
+	for _, tc := range tests {
+		if got := prefixProblem(tc.prefix); (got == "") != tc.ok {
+			t.Errorf("prefixProblem(%q) = %q", tc.prefix, got)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Quote to jedna złota myśl razem z tym, kto ją powiedział i kto ją dodał.
+type Quote struct {
+	// ID jest stałe w obrębie serwera i nie wraca po usunięciu cytatu.
+	ID          int       `json:"id"`
+	Text        string    `json:"text"`
+	Author      string    `json:"author,omitempty"`
+	SubmitterID string    `json:"submitter_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at,omitzero"`
+	Source      string    `json:"source,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	// DeletedAt i DeletedBy są ustawione tylko dla cytatów w koszu.
+	DeletedAt time.Time `json:"deleted_at,omitzero"`
+	DeletedBy string    `json:"deleted_by,omitempty"`
+}
+
+// UnmarshalJSON przyjmuje też gołe stringi, w jakich starsze config.json
+// trzymały cytaty.
+func (q *Quote) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*q = Quote{Text: text}
+		return nil
+	}
+	type plain Quote
+	return json.Unmarshal(data, (*plain)(q))
+}
+
+// parseQuoteArgs rozbiera argumenty !dodaj w postaci
+// "#tag1 #tag2 tekst | autor | źródło"; tagi, autor i źródło są opcjonalne.
+func parseQuoteArgs(args string) Quote {
+	tags, rest := parseTags(args)
+	parts := strings.SplitN(rest, "|", 3)
+	q := Quote{Text: strings.TrimSpace(parts[0]), Tags: tags}
+	if len(parts) > 1 {
+		q.Author = strings.TrimSpace(parts[1])
+	}
+	if len(parts) > 2 {
+		q.Source = strings.TrimSpace(parts[2])
+	}
+	return q
+}
+
+func handleAdd(ctx *commandContext, args string) {
+	args, force := cutForceFlag(args)
+	addQuote(ctx, parseQuoteArgs(args), force)
+}
+
+// addQuote dodaje cytat z !dodaj albo /dodaj i odpowiada wynikiem.
+func addQuote(ctx *commandContext, quote Quote, force bool) {
+	if quote.Text == "" {
+		ctx.reply("❌ Podaj treść złotej myśli!")
+		return
+	}
+	command := ctx.cmd("dodaj")
+	if ctx.slash() {
+		command = "/dodaj"
+	}
+	reply, _ := submitQuote(ctx.s, ctx.GuildID, ctx.UserID, command, quote, force)
+	ctx.reply(reply)
+}
+
+// submitQuote to wspólna droga nowego cytatu z !dodaj, !zapisz i menu
+// kontekstowego: sprawdza powtórki, a potem dodaje cytat albo odkłada go do
+// moderacji. Zwraca odpowiedź dla użytkownika i czy cytat przyjęto.
+func submitQuote(s *discordgo.Session, guildID, userID, command string, quote Quote, force bool) (string, bool) {
+	settings, err := store.Settings(guildID)
+	if err != nil {
+		return storeErrorMessage(command, err), false
+	}
+	if !force {
+		quotes, err := store.Quotes(guildID)
+		var pending []Quote
+		if err == nil {
+			pending, err = store.Pending(guildID)
+		}
+		if err != nil {
+			return storeErrorMessage(command, err), false
+		}
+		if dup, score, ok := findDuplicate(quotes, quote.Text); ok {
+			what := "Taka złota myśl już jest"
+			if score < 1 {
+				what = fmt.Sprintf("Bardzo podobna złota myśl już jest (%.0f%% podobieństwa)", score*100)
+			}
+			hint := command + " --force ..."
+			if strings.HasPrefix(command, "/") {
+				hint = command + " z opcją force"
+			}
+			return fmt.Sprintf("❌ %s: #%d *%s*\nJeśli to na pewno coś innego, użyj %s",
+				what, dup.ID, truncateRunes(dup.Text, 200), hint), false
+		}
+		if dup, _, ok := findDuplicate(pending, quote.Text); ok {
+			return fmt.Sprintf("❌ Taka złota myśl czeka już na akceptację (zgłoszenie #%d).", dup.ID), false
+		}
+	}
+	quote.SubmitterID = userID
+	quote.CreatedAt = time.Now()
+	if settings.ModChannelID != "" {
+		return submitForModeration(s, guildID, command, settings.ModChannelID, quote)
+	}
+	quote, err = store.AddQuote(guildID, quote)
+	if err != nil {
+		return storeErrorMessage(command, err), false
+	}
+	if err := addToDailyBag(guildID, quote.ID); err != nil {
+		log.Println("daily bag error:", err)
+	}
+	return fmt.Sprintf("✅ Dodano nową złotą myśl #%d!", quote.ID), true
+}
+
+// parseQuoteID przyjmuje ID w postaci "12" albo "#12".
+func parseQuoteID(s string) (int, bool) {
+	id, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(s), "#"))
+	return id, err == nil && id > 0
+}
+
+// parseTags zdejmuje z początku args słowa zaczynające się od # i zwraca je
+// znormalizowane, bez powtórzeń, razem z resztą tekstu.
+func parseTags(args string) ([]string, string) {
+	var tags []string
+	rest := strings.TrimSpace(args)
+	for strings.HasPrefix(rest, "#") {
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			end = len(rest)
+		}
+		word, after := rest[:end], rest[end:]
+		if tag := normalizeTag(word); tag != "" && !containsString(tags, tag) {
+			tags = append(tags, tag)
+		}
+		rest = strings.TrimSpace(after)
+	}
+	return tags, rest
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimLeft(strings.TrimSpace(tag), "#"))
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTag zwraca true dla pustego tagu, żeby "bez filtra" nie wymagało osobnej ścieżki.
+func (q Quote) hasTag(tag string) bool {
+	tag = normalizeTag(tag)
+	return tag == "" || containsString(q.Tags, tag)
+}
+
+func filterByTag(quotes []Quote, tag string) []Quote {
+	if normalizeTag(tag) == "" {
+		return quotes
+	}
+	var out []Quote
+	for _, q := range quotes {
+		if q.hasTag(tag) {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// truncateRunes skraca s do max run, nie rozcinając polskich znaków.
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
+
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return "#" + strings.Join(tags, " #")
+}
+
+// attribution zwraca linię "— Autor, dodane przez @user" albo "", gdy cytat
+// nie ma ani autora, ani zgłaszającego (np. po migracji ze starego formatu).
+func (q Quote) attribution() string {
+	var parts []string
+	if q.Author != "" {
+		parts = append(parts, q.Author)
+	}
+	if q.SubmitterID != "" {
+		parts = append(parts, fmt.Sprintf("dodane przez <@%s>", q.SubmitterID))
+	}
+	if len(parts) == 0 && q.Source == "" {
+		return ""
+	}
+	line := "— " + strings.Join(parts, ", ")
+	if q.Source != "" {
+		if len(parts) == 0 {
+			line = "— źródło: " + q.Source
+		} else {
+			line += " (" + q.Source + ")"
+		}
+	}
+	return line
+}
+
+func formatQuote(header string, q Quote) string {
+	msg := fmt.Sprintf("%s\n\n*%s*", header, q.Text)
+	if attr := q.attribution(); attr != "" {
+		msg += "\n" + attr
+	}
+	if len(q.Tags) > 0 {
+		msg += "\n🏷️ " + formatTags(q.Tags)
+	}
+	return msg
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+)
+
+// Złota myśl dnia jest losowana "z worka": worek to przetasowana lista ID
+// cytatów, z której co rano bierzemy pierwszy element. Dopiero gdy worek się
+// opróżni, tasujemy wszystkie cytaty od nowa - każdy pojawi się raz, zanim
+// któryś się powtórzy. Worek jest w Store, więc przeżywa restart bota.
+
+// nextDailyQuote wyciąga z worka kolejny cytat spośród pool. ID, których nie
+// ma już w pool (usunięte albo spoza tagu dnia), są po drodze pomijane.
+func nextDailyQuote(guildID string, pool []Quote) (Quote, error) {
+	byID := make(map[int]Quote, len(pool))
+	for _, q := range pool {
+		byID[q.ID] = q
+	}
+	var picked Quote
+	err := store.UpdateDailyBag(guildID, func(bag []int) []int {
+		for len(bag) > 0 {
+			q, ok := byID[bag[0]]
+			bag = bag[1:]
+			if ok {
+				picked = q
+				return bag
+			}
+		}
+		bag = make([]int, 0, len(pool))
+		for _, q := range pool {
+			bag = append(bag, q.ID)
+		}
+		rand.Shuffle(len(bag), func(i, j int) { bag[i], bag[j] = bag[j], bag[i] })
+		picked = byID[bag[0]]
+		return bag[1:]
+	})
+	return picked, err
+}
+
+// addToDailyBag wkłada nowe cytaty w losowe miejsca bieżącego worka, żeby
+// pojawiły się jeszcze w tym cyklu. Pusty worek zostawiamy - przy tasowaniu
+// i tak obejmie wszystkie cytaty.
+func addToDailyBag(guildID string, ids ...int) error {
+	return store.UpdateDailyBag(guildID, func(bag []int) []int {
+		if len(bag) == 0 {
+			return bag
+		}
+		for _, id := range ids {
+			if !slices.Contains(bag, id) {
+				bag = slices.Insert(bag, rand.Intn(len(bag)+1), id)
+			}
+		}
+		return bag
+	})
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestDailyBagStore sprawdza, że worek przeżywa kolejne podmiany w magazynie.
+func TestDailyBagStore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, st Store) {
+		st.UpdateDailyBag(testGuild, func(bag []int) []int {
+			if len(bag) != 0 {
+				t.Errorf("nowy worek = %v", bag)
+			}
+			return []int{3, 1, 2}
+		})
+		st.UpdateDailyBag(testGuild, func(bag []int) []int {
+			if !slices.Equal(bag, []int{3, 1, 2}) {
+				t.Errorf("worek = %v, chcę [3 1 2]", bag)
+			}
+			return bag[1:]
+		})
+		st.UpdateDailyBag(testGuild, func(bag []int) []int {
+			if !slices.Equal(bag, []int{1, 2}) {
+				t.Errorf("worek = %v, chcę [1 2]", bag)
+			}
+			return bag
+		})
+	})
+}
+
+// useStore podmienia globalny magazyn na czas testu.
+func useStore(t *testing.T, st Store) {
+	old := store
+	store = st
+	t.Cleanup(func() { store = old })
+}
+
+func TestNextDailyQuote(t *testing.T) {
+	pool := []Quote{{ID: 1}, {ID: 2}, {ID: 3}}
+	tests := []struct {
+		name    string
+		bag     []int
+		pool    []Quote
+		want    int
+		wantBag []int
+	}{
+		{"bierze pierwszy z worka", []int{2, 3, 1}, pool, 2, []int{3, 1}},
+		{"pomija usunięte", []int{9, 8, 3, 1}, pool, 3, []int{1}},
+		{"pomija spoza tagu dnia", []int{1, 2, 3}, pool[2:], 3, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			forEachStore(t, func(t *testing.T, st Store) {
+				useStore(t, st)
+				st.UpdateDailyBag(testGuild, func([]int) []int { return tc.bag })
+				got, err := nextDailyQuote(testGuild, tc.pool)
+				if err != nil || got.ID != tc.want {
+					t.Fatalf("nextDailyQuote = %d, %v, chcę %d", got.ID, err, tc.want)
+				}
+				st.UpdateDailyBag(testGuild, func(bag []int) []int {
+					if !slices.Equal(bag, tc.wantBag) {
+						t.Errorf("worek = %v, chcę %v", bag, tc.wantBag)
+					}
+					return bag
+				})
+			})
+		})
+	}
+}
+
+// TestNextDailyQuoteCycle sprawdza, że po opróżnieniu worka każdy cytat
+// wypada raz, zanim któryś się powtórzy.
+func TestNextDailyQuoteCycle(t *testing.T) {
+	forEachStore(t, func(t *testing.T, st Store) {
+		useStore(t, st)
+		pool := []Quote{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}}
+		var seen []int
+		for range 2 * len(pool) {
+			q, err := nextDailyQuote(testGuild, pool)
+			if err != nil {
+				t.Fatal(err)
+			}
+			seen = append(seen, q.ID)
+		}
+		for _, cycle := range [][]int{seen[:4], seen[4:]} {
+			cycle = slices.Clone(cycle)
+			slices.Sort(cycle)
+			if !slices.Equal(cycle, []int{1, 2, 3, 4}) {
+				t.Errorf("cykl = %v, chcę każdego cytatu raz", seen)
+			}
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// polishFold sprowadza polskie litery do ich odpowiedników bez ogonków.
+// Każda runa przechodzi w dokładnie jedną runę, więc indeksy run w tekście
+// złożonym i oryginalnym się pokrywają - z tego korzysta podświetlanie.
+var polishFold = map[rune]rune{
+	'ą': 'a', 'ć': 'c', 'ę': 'e', 'ł': 'l', 'ń': 'n',
+	'ó': 'o', 'ś': 's', 'ź': 'z', 'ż': 'z',
+}
+
+func foldRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		r = unicode.ToLower(r)
+		if f, ok := polishFold[r]; ok {
+			r = f
+		}
+		runes[i] = r
+	}
+	return runes
+}
+
+func foldText(s string) string {
+	return string(foldRunes(s))
+}
+
+// searchTerms rozbija zapytanie na złożone słowa; cytat pasuje, gdy zawiera
+// każde z nich w treści, autorze albo tagach.
+func searchTerms(query string) []string {
+	var terms []string
+	for _, w := range strings.Fields(foldText(query)) {
+		if w = strings.Trim(w, "#"); w != "" {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+func matchesSearch(q Quote, terms []string) bool {
+	haystack := foldText(q.Text + "\n" + q.Author + "\n" + strings.Join(q.Tags, " "))
+	for _, t := range terms {
+		if !strings.Contains(haystack, t) {
+			return false
+		}
+	}
+	return len(terms) > 0
+}
+
+// indexRunes szuka needle w haystack i zwraca indeks w runach albo -1.
+func indexRunes(haystack, needle []rune, from int) int {
+	for i := from; i+len(needle) <= len(haystack); i++ {
+		match := true
+		for j := range needle {
+			if haystack[i+j] != needle[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+// highlight pogrubia w text wszystkie wystąpienia terms i przycina wynik do
+// okna około maxRunes wokół pierwszego trafienia.
+func highlight(text string, terms []string, maxRunes int) string {
+	orig := []rune(text)
+	folded := foldRunes(text)
+	marked := make([]bool, len(orig))
+	first := -1
+	for _, t := range terms {
+		needle := []rune(t)
+		for i := indexRunes(folded, needle, 0); i >= 0; i = indexRunes(folded, needle, i+len(needle)) {
+			for j := i; j < i+len(needle); j++ {
+				marked[j] = true
+			}
+			if first < 0 || i < first {
+				first = i
+			}
+		}
+	}
+
+	start, end := 0, len(orig)
+	if len(orig) > maxRunes {
+		if first > maxRunes/3 {
+			start = first - maxRunes/3
+		}
+		end = min(start+maxRunes, len(orig))
+		start = max(0, end-maxRunes)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	bold := false
+	for i := start; i < end; i++ {
+		if marked[i] != bold {
+			b.WriteString("**")
+			bold = marked[i]
+		}
+		b.WriteRune(orig[i])
+	}
+	if bold {
+		b.WriteString("**")
+	}
+	if end < len(orig) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// searchResultLine buduje linię wyniku: numer, fragment treści i - jeśli
+// trafienie jest tylko w autorze lub tagach - także te pola.
+func searchResultLine(num int, q Quote, terms []string) string {
+	line := fmt.Sprintf("%d. %s", num, highlight(q.Text, terms, 90))
+	folded := foldText(q.Author)
+	for _, t := range terms {
+		if q.Author != "" && strings.Contains(folded, t) {
+			line += " — " + highlight(q.Author, terms, 40)
+			break
+		}
+	}
+	var tags []string
+	for _, tag := range q.Tags {
+		for _, t := range terms {
+			if strings.Contains(foldText(tag), t) {
+				tags = append(tags, "**#"+tag+"**")
+				break
+			}
+		}
+	}
+	if len(tags) > 0 {
+		line += " " + strings.Join(tags, " ")
+	}
+	return line
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"Żółć #Praca  ", []string{"zolc", "praca"}},
+		{"##", nil},
+		{"", nil},
+	}
+	for _, tc := range tests {
+		if got := searchTerms(tc.query); !slices.Equal(got, tc.want) {
+			t.Errorf("searchTerms(%q) = %q, chcę %q", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestMatchesSearch(t *testing.T) {
+	q := Quote{Text: "Nie ma róży bez kolców", Author: "Przysłowie", Tags: []string{"mądrość"}}
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"rozy", true},
+		{"rozy przyslowie", true},
+		{"#madrosc", true},
+		{"rozy kot", false},
+		{"", false},
+	}
+	for _, tc := range tests {
+		if got := matchesSearch(q, searchTerms(tc.query)); got != tc.want {
+			t.Errorf("matchesSearch(%q) = %v", tc.query, got)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text     string
+		terms    []string
+		maxRunes int
+		want     string
+	}{
+		{"Żółć i łza", []string{"zolc"}, 100, "**Żółć** i łza"},
+		{"ala ma kota", []string{"a"}, 100, "**a**l**a** m**a** kot**a**"},
+		{"kot", []string{"ko", "ot"}, 100, "**kot**"},
+		{"abc", []string{"x"}, 100, "abc"},
+		{"cel" + strings.Repeat("y", 100), []string{"cel"}, 30, "**cel**" + strings.Repeat("y", 27) + "…"},
+		{strings.Repeat("x", 50) + "cel" + strings.Repeat("y", 50), []string{"cel"}, 30,
+			"…" + strings.Repeat("x", 10) + "**cel**" + strings.Repeat("y", 17) + "…"},
+	}
+	for _, tc := range tests {
+		if got := highlight(tc.text, tc.terms, tc.maxRunes); got != tc.want {
+			t.Errorf("highlight(%q, %q) = %q, chcę %q", tc.text, tc.terms, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var minQuoteID = 1.0
+
+// textChannels ogranicza opcje kanału do kanałów, na które bot może pisać.
+var textChannels = []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews}
+
+// slashCommands to komendy ukośnikowe. Każda trafia do tego samego handlera
+// co jej odpowiednik z "!" (zob. slashHandlers).
+var slashCommands = []*discordgo.ApplicationCommand{
+	{
+		Name:        "zm",
+		Description: "Losowa złota myśl",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionString, Name: "tag", Description: "Losuj tylko spośród myśli z tym tagiem", Autocomplete: true},
+		},
+	},
+	{
+		Name:        "dodaj",
+		Description: "Dodaj nową złotą myśl",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionString, Name: "tekst", Description: "Treść złotej myśli - pomiń, żeby wpisać dłuższą w osobnym oknie"},
+			{Type: discordgo.ApplicationCommandOptionString, Name: "autor", Description: "Kto to powiedział"},
+			{Type: discordgo.ApplicationCommandOptionString, Name: "zrodlo", Description: "Skąd pochodzi (książka, link...)"},
+			{Type: discordgo.ApplicationCommandOptionString, Name: "tagi", Description: "Tagi oddzielone spacjami, np. praca motywacja", Autocomplete: true},
+			{Type: discordgo.ApplicationCommandOptionBoolean, Name: "force", Description: "Dodaj mimo podobnej złotej myśli"},
+		},
+	},
+	{
+		Name:        "usun",
+		Description: "Przenieś złotą myśl do kosza",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionInteger, Name: "numer", Description: "Numer złotej myśli", Required: true, MinValue: &minQuoteID, Autocomplete: true},
+		},
+	},
+	{
+		Name:        "lista",
+		Description: "Lista złotych myśli",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionString, Name: "tag", Description: "Pokaż tylko myśli z tym tagiem", Autocomplete: true},
+		},
+	},
+	{
+		Name:        "kanal",
+		Description: "Ustaw kanał złotej myśli dnia (9:00)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionChannel, Name: "kanal", Description: "Kanał (domyślnie bieżący)", ChannelTypes: textChannels},
+		},
+	},
+	{
+		Name:        "gem",
+		Description: "Wygeneruj wykres ETF-ów z ostatniego roku",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionString, Name: "ticker", Description: "Tylko jeden fundusz (domyślnie wszystkie)", Autocomplete: true},
+		},
+	},
+	{
+		Name:        "gemsubscribe",
+		Description: "Wysyłaj wykres ETF-ów co miesiąc",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionChannel, Name: "kanal", Description: "Kanał (domyślnie bieżący)", ChannelTypes: textChannels},
+		},
+	},
+	{
+		Name:        "pogoda",
+		Description: "Prognoza pogody na jutro",
+	},
+}
+
+// slashOptions to opcje komendy ukośnikowej po nazwie.
+type slashOptions map[string]*discordgo.ApplicationCommandInteractionDataOption
+
+// string zwraca wartość opcji tekstowej albo ID kanału; "" gdy jej nie podano.
+func (o slashOptions) string(name string) string {
+	if opt, ok := o[name]; ok {
+		if v, ok := opt.Value.(string); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+func (o slashOptions) int(name string) int {
+	if opt, ok := o[name]; ok && opt.Type == discordgo.ApplicationCommandOptionInteger {
+		return int(opt.IntValue())
+	}
+	return 0
+}
+
+func (o slashOptions) bool(name string) bool {
+	if opt, ok := o[name]; ok && opt.Type == discordgo.ApplicationCommandOptionBoolean {
+		return opt.BoolValue()
+	}
+	return false
+}
+
+var slashHandlers = map[string]func(ctx *commandContext, opts slashOptions){
+	"zm": func(ctx *commandContext, opts slashOptions) {
+		sendRandomQuote(ctx, normalizeTag(opts.string("tag")))
+	},
+	"dodaj": func(ctx *commandContext, opts slashOptions) {
+		tags, _ := parseTags(tagsAsHashes(strings.ReplaceAll(opts.string("tagi"), ",", " ")))
+		addQuote(ctx, Quote{
+			Text:   opts.string("tekst"),
+			Author: opts.string("autor"),
+			Source: opts.string("zrodlo"),
+			Tags:   tags,
+		}, opts.bool("force"))
+	},
+	"usun": func(ctx *commandContext, opts slashOptions) {
+		handleDelete(ctx, strconv.Itoa(opts.int("numer")))
+	},
+	"lista": func(ctx *commandContext, opts slashOptions) {
+		sendPaginatedList(ctx, normalizeTag(opts.string("tag")))
+	},
+	"kanal": func(ctx *commandContext, opts slashOptions) {
+		handleChannel(ctx, opts.string("kanal"))
+	},
+	"gem": func(ctx *commandContext, opts slashOptions) {
+		handleGem(ctx, opts.string("ticker"))
+	},
+	"gemsubscribe": func(ctx *commandContext, opts slashOptions) {
+		handleGemSubscribe(ctx, opts.string("kanal"))
+	},
+	"pogoda": func(ctx *commandContext, opts slashOptions) {
+		handleWeather(ctx)
+	},
+}
+
+// handleSlashCommand sprawdza dostęp tak jak dla komendy z "!", odracza
+// odpowiedź (wykres czy pogoda potrafią trwać dłużej niż 3 sekundy) i
+// przekazuje opcje do handlera.
+func handleSlashCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	handler, ok := slashHandlers[data.Name]
+	if !ok {
+		return
+	}
+	if ok, msg := checkInteractionAccess(i, commandName(data.Name, "")); !ok {
+		respondEphemeral(s, i, msg)
+		return
+	}
+	opts := slashOptions{}
+	for _, opt := range data.Options {
+		opts[opt.Name] = opt
+	}
+	// /dodaj bez treści otwiera okno; okno musi być pierwszą odpowiedzią,
+	// więc nie odraczamy.
+	if data.Name == "dodaj" && opts.string("tekst") == "" {
+		draft := &addDraft{tags: opts.string("tagi"), force: opts.bool("force")}
+		draft.quote = Quote{Author: opts.string("autor"), Source: opts.string("zrodlo")}
+		openAddModal(s, i, 0, draft)
+		return
+	}
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		log.Println("interaction defer error:", err)
+		return
+	}
+	ctx := interactionContext(s, i)
+	handler(ctx, opts)
+	ctx.finish()
+}
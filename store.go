@@ -0,0 +1,246 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Store to warstwa trwałego stanu bota. Wszystkie dane są kluczowane ID
+// serwera; nieznany serwer jest zakładany przy pierwszym zapisie, a do
+// tego czasu odczyty widzą go z domyślnymi cytatami.
+type Store interface {
+	// Guilds zwraca ID wszystkich serwerów, które mają zapisany stan.
+	Guilds() ([]string, error)
+
+	Settings(guildID string) (GuildSettings, error)
+	UpdateSettings(guildID string, fn func(*GuildSettings)) error
+
+	Quotes(guildID string) ([]Quote, error)
+	// AddQuote nadaje cytatowi kolejne, nigdy niepowtarzane ID serwera
+	// i zwraca go z tym ID.
+	AddQuote(guildID string, q Quote) (Quote, error)
+	// ImportQuotes dodaje cytaty jednym zapisem i zwraca je z nadanymi ID.
+	// Przy replace w tym samym zapisie przenosi wszystkie dotychczasowe
+	// cytaty do kosza i zwraca ich liczbę.
+	ImportQuotes(guildID string, quotes []Quote, replace bool, deletedBy string) ([]Quote, int, error)
+	// DeleteQuote przenosi cytat do kosza; ID pozostaje zajęte.
+	DeleteQuote(guildID string, id int, deletedBy string) error
+	// Trash zwraca cytaty z kosza, od ostatnio usuniętego.
+	Trash(guildID string) ([]Quote, error)
+	RestoreQuote(guildID string, id int) (Quote, error)
+	// PurgeTrash trwale usuwa ze wszystkich serwerów cytaty wyrzucone do
+	// kosza przed before i zwraca ich liczbę.
+	PurgeTrash(before time.Time) (int, error)
+	Quote(guildID string, id int) (Quote, error)
+	// EditQuote odkłada obecną wersję cytatu do historii, a potem stosuje fn.
+	// ID, zgłaszający i data dodania zostają nietknięte.
+	EditQuote(guildID string, id int, editorID string, fn func(*Quote)) (Quote, error)
+	// QuoteRevisions zwraca poprzednie wersje cytatu, od najstarszej.
+	QuoteRevisions(guildID string, id int) ([]QuoteRevision, error)
+
+	// AddPending odkłada zgłoszony cytat do kolejki moderacji i zwraca go
+	// z numerem zgłoszenia, niezależnym od ID cytatów.
+	AddPending(guildID string, q Quote) (Quote, error)
+	// Pending zwraca zgłoszenia czekające na decyzję, od najstarszego.
+	Pending(guildID string) ([]Quote, error)
+	// ApprovePending przenosi zgłoszenie do cytatów i zwraca je z nowym ID
+	// cytatu. Już rozpatrzone zgłoszenie daje errPendingNotFound.
+	ApprovePending(guildID string, id int) (Quote, error)
+	// RejectPending usuwa zgłoszenie i zwraca je.
+	RejectPending(guildID string, id int) (Quote, error)
+
+	// UpdateDailyBag atomowo podmienia worek ID cytatów, z którego losujemy
+	// złotą myśl dnia, na wynik fn. fn nie może wołać Store.
+	UpdateDailyBag(guildID string, fn func(bag []int) []int) error
+
+	// RecordPost zapamiętuje, że wiadomość messageID pokazuje cytat quoteID,
+	// i zapomina posty starsze niż unvotedPostTTL, pod którymi nikt nie głosował.
+	RecordPost(guildID, messageID string, quoteID int) error
+	// SetVote dodaje (on) albo zdejmuje głos value (+1/-1) użytkownika pod
+	// wiadomością. Dla wiadomości spoza RecordPost zwraca errPostNotFound.
+	SetVote(guildID, messageID, userID string, value int, on bool) error
+	// Scores zwraca sumę głosów każdego cytatu, który ma choć jeden głos.
+	Scores(guildID string) (map[int]int, error)
+
+	// RecordDaily zapisuje złotą myśl dnia; drugi wpis z tą samą datą
+	// zastępuje poprzedni.
+	RecordDaily(guildID string, entry DailyEntry) error
+	// DailyHistory zwraca do limit ostatnich złotych myśli dnia, od najnowszej.
+	DailyHistory(guildID string, limit int) ([]DailyEntry, error)
+	// DailyOn zwraca wpis z dnia date (RRRR-MM-DD) albo errDailyNotFound.
+	DailyOn(guildID, date string) (DailyEntry, error)
+
+	// CommandAccess zwraca reguły dostępu nadpisane na serwerze, kluczowane
+	// nazwą komendy bez "!".
+	CommandAccess(guildID string) (map[string]AccessRule, error)
+	// SetCommandAccess nadpisuje regułę komendy; nil przywraca domyślną.
+	SetCommandAccess(guildID, command string, rule *AccessRule) error
+
+	GemSubscribers(guildID string) ([]string, error)
+	// AddGemSubscriber zwraca false, jeśli użytkownik był już zapisany.
+	AddGemSubscriber(guildID, userID string) (bool, error)
+
+	Close() error
+}
+
+// unvotedPostTTL to czas, przez który post z cytatem czeka na pierwszy głos.
+// Posty z głosami trzymamy zawsze, bo składają się na wyniki cytatów.
+const unvotedPostTTL = 30 * 24 * time.Hour
+
+// GuildSettings to ustawienia serwera niezależne od listy cytatów.
+type GuildSettings struct {
+	ChannelID    string `json:"channel_id"`
+	GemChannelID string `json:"gem_channel_id"`
+	// DailyTag zawęża złotą myśl dnia do jednego tagu; pusty = wszystkie cytaty.
+	DailyTag string `json:"daily_tag,omitempty"`
+	// WeightedRandom sprawia, że !zm częściej losuje cytaty z lepszym wynikiem głosowania.
+	WeightedRandom bool `json:"weighted_random,omitempty"`
+	// ModChannelID włącza moderację: !dodaj trafia do kolejki, a zgłoszenia
+	// są ogłaszane na tym kanale. Pusty = cytaty dodawane od razu.
+	ModChannelID string `json:"mod_channel_id,omitempty"`
+	// PlainText wyłącza embedy: cytaty, pogoda i wykres ETF idą jako zwykły tekst.
+	PlainText bool `json:"plain_text,omitempty"`
+	// Prefix to prefiks komend serwera; pusty = "!".
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// DailyEntry to jeden poranny post ze złotą myślą dnia.
+type DailyEntry struct {
+	// Date to dzień w strefie Europe/Warsaw, w formacie dailyDateLayout.
+	Date      string    `json:"date"`
+	ChannelID string    `json:"channel_id"`
+	QuoteID   int       `json:"quote_id"`
+	MessageID string    `json:"message_id"`
+	PostedAt  time.Time `json:"posted_at"`
+}
+
+const dailyDateLayout = "2006-01-02"
+
+// QuoteRevision to poprzednia wersja cytatu, zastąpiona przez EditedBy
+// w chwili EditedAt.
+type QuoteRevision struct {
+	Text     string    `json:"text"`
+	Author   string    `json:"author,omitempty"`
+	Source   string    `json:"source,omitempty"`
+	Tags     []string  `json:"tags,omitempty"`
+	EditedBy string    `json:"edited_by,omitempty"`
+	EditedAt time.Time `json:"edited_at"`
+}
+
+// revisionOf zapisuje treść cytatu jako wersję zastąpioną przez editorID.
+func revisionOf(q Quote, editorID string) QuoteRevision {
+	return QuoteRevision{
+		Text:     q.Text,
+		Author:   q.Author,
+		Source:   q.Source,
+		Tags:     append([]string(nil), q.Tags...),
+		EditedBy: editorID,
+		EditedAt: time.Now(),
+	}
+}
+
+// applyEdit wykonuje fn na kopii q, pilnując pól, których edycja nie zmienia.
+func applyEdit(q Quote, fn func(*Quote)) Quote {
+	edited := q
+	edited.Tags = append([]string(nil), q.Tags...)
+	fn(&edited)
+	edited.ID, edited.SubmitterID, edited.CreatedAt = q.ID, q.SubmitterID, q.CreatedAt
+	return edited
+}
+
+var (
+	errQuoteNotFound   = errors.New("nie ma cytatu o takim ID")
+	errPostNotFound    = errors.New("wiadomość nie jest postem z cytatem")
+	errDailyNotFound   = errors.New("brak złotej myśli dnia z tej daty")
+	errPendingNotFound = errors.New("nie ma zgłoszenia o takim numerze")
+)
+
+var defaultQuotes = []string{
+	"Wytrwałość to klucz do sukcesu.",
+	"Każdy dzień to nowa szansa.",
+	"Wierz w siebie i swoje możliwości.",
+}
+
+// newDefaultQuotes zwraca startowe cytaty dla nowego serwera, z ID od 1.
+func newDefaultQuotes() []Quote {
+	quotes := make([]Quote, len(defaultQuotes))
+	for i, text := range defaultQuotes {
+		quotes[i] = Quote{ID: i + 1, Text: text}
+	}
+	return quotes
+}
+
+// dataDir to katalog ze stanem bota. W Dockerze montujemy cały katalog, bo
+// zapis przez rename nie podmieni pojedynczego zamontowanego pliku.
+const dataDir = "data"
+
+// legacyConfigFile to dawne miejsce config.json, obok binarki.
+const legacyConfigFile = "config.json"
+
+// moveLegacyConfig przenosi config.json ze starego miejsca do dataDir.
+func moveLegacyConfig() error {
+	if _, err := os.Stat(configFile); !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if _, err := os.Stat(legacyConfigFile); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return err
+	}
+	if err := os.Rename(legacyConfigFile, configFile); err != nil {
+		return fmt.Errorf("przenieś ręcznie %s do %s: %w", legacyConfigFile, configFile, err)
+	}
+	log.Printf("Przeniesiono %s do %s", legacyConfigFile, configFile)
+	return nil
+}
+
+// importConfigFile przenosi config.json do świeżej bazy SQLite, żeby
+// przejście z STORAGE=json nie gubiło danych. Plik zostaje jako kopia.
+func importConfigFile(ss *sqliteStore, guildOf func(channelID string) (string, error)) error {
+	if _, err := os.Stat(configFile); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	js, err := openJSONStore(configFile, guildOf)
+	if err != nil {
+		return err
+	}
+	n, err := ss.importConfigJSON(js)
+	if n > 0 {
+		log.Printf("Zaimportowano %s do bazy SQLite (serwery: %d)", configFile, n)
+	}
+	return err
+}
+
+// openStore wybiera backend na podstawie zmiennej STORAGE (json|sqlite).
+// guildOf podaje serwer kanału (zob. jsonStore.claimDefault).
+func openStore(guildOf func(channelID string) (string, error)) (Store, error) {
+	if err := moveLegacyConfig(); err != nil {
+		return nil, err
+	}
+	switch backend := os.Getenv("STORAGE"); backend {
+	case "", "json":
+		return openJSONStore(configFile, guildOf)
+	case "sqlite":
+		path := os.Getenv("SQLITE_PATH")
+		if path == "" {
+			path = filepath.Join(dataDir, "zlotemysli.db")
+		}
+		ss, err := openSQLiteStore(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := importConfigFile(ss, guildOf); err != nil {
+			ss.Close()
+			return nil, fmt.Errorf("import %s: %w", configFile, err)
+		}
+		return ss, nil
+	default:
+		return nil, fmt.Errorf("nieznany backend STORAGE=%q (dostępne: json, sqlite)", backend)
+	}
+}
@@ -0,0 +1,671 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GuildConfig to stan bota dla jednego serwera w config.json.
+type GuildConfig struct {
+	GuildSettings
+	Quotes         []Quote  `json:"quotes"`
+	NextQuoteID    int      `json:"next_quote_id"`
+	GemSubscribers []string `json:"gem_subscribers"`
+	Trash          []Quote  `json:"trash,omitempty"`
+	DailyBag       []int    `json:"daily_bag,omitempty"`
+	// Pending to zgłoszenia czekające na moderację; ich ID to numery
+	// zgłoszeń z NextPendingID, a nie ID cytatów.
+	Pending       []Quote `json:"pending,omitempty"`
+	NextPendingID int     `json:"next_pending_id,omitempty"`
+	// Posts łączy ID wiadomości bota z pokazanym w niej cytatem i głosami pod nią.
+	Posts map[string]*QuotePost `json:"posts,omitempty"`
+	// Access to nadpisane reguły dostępu do komend.
+	Access map[string]AccessRule `json:"access,omitempty"`
+	// History to kolejne złote myśli dnia, posortowane po dacie.
+	History []DailyEntry `json:"history,omitempty"`
+	// Revisions trzyma poprzednie wersje cytatów, kluczowane ID cytatu.
+	Revisions map[int][]QuoteRevision `json:"revisions,omitempty"`
+}
+
+type QuotePost struct {
+	QuoteID  int       `json:"quote_id"`
+	PostedAt time.Time `json:"posted_at"`
+	Up       []string  `json:"up,omitempty"`
+	Down     []string  `json:"down,omitempty"`
+}
+
+type Config struct {
+	Guilds map[string]*GuildConfig `json:"guilds"`
+}
+
+// legacyConfig to format config.json sprzed podziału na serwery.
+type legacyConfig struct {
+	Quotes         []Quote  `json:"quotes"`
+	ChannelID      string   `json:"channel_id"`
+	GemChannelID   string   `json:"gem_channel_id"`
+	GemSubscribers []string `json:"gem_subscribers"`
+}
+
+// defaultGuildKey trzyma zmigrowaną konfigurację, dopóki nie ustalimy jej
+// serwera (zob. claimDefault). Nie jest zwracany przez Guilds.
+const defaultGuildKey = "default"
+
+var configFile = filepath.Join(dataDir, "config.json")
+
+// jsonStore trzyma cały stan w pamięci i przepisuje plik po każdej zmianie.
+// Handlery discordgo i zadania crona działają równolegle, więc każda metoda
+// bierze mu.
+type jsonStore struct {
+	mu     sync.Mutex
+	path   string
+	config Config
+}
+
+// openJSONStore wczytuje path. guildOf podaje serwer kanału i służy do
+// przypisania starej konfiguracji; może być nil.
+func openJSONStore(path string, guildOf func(channelID string) (string, error)) (*jsonStore, error) {
+	js := &jsonStore{
+		path:   path,
+		config: Config{Guilds: map[string]*GuildConfig{}},
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return js, js.save()
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Uszkodzonego pliku nie nadpisujemy pustą konfiguracją - niech zobaczy to człowiek.
+	if err := json.Unmarshal(data, &js.config); err != nil {
+		return nil, fmt.Errorf("%s jest uszkodzony: %w", path, err)
+	}
+	if js.config.Guilds == nil {
+		js.config.Guilds = map[string]*GuildConfig{}
+	}
+
+	var legacy legacyConfig
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return nil, fmt.Errorf("%s jest uszkodzony: %w", path, err)
+	}
+	if len(js.config.Guilds) == 0 && (legacy.Quotes != nil || legacy.ChannelID != "" || legacy.GemChannelID != "" || len(legacy.GemSubscribers) > 0) {
+		js.config.Guilds[defaultGuildKey] = &GuildConfig{
+			GuildSettings: GuildSettings{
+				ChannelID:    legacy.ChannelID,
+				GemChannelID: legacy.GemChannelID,
+			},
+			Quotes:         legacy.Quotes,
+			GemSubscribers: legacy.GemSubscribers,
+		}
+		log.Println("Zmigrowano starą konfigurację z", path)
+	}
+
+	migrated := false
+	for key, gc := range js.config.Guilds {
+		if gc.NextQuoteID == 0 {
+			assignQuoteIDs(gc)
+			log.Printf("Nadano stałe ID cytatom serwera %q", key)
+			migrated = true
+		}
+		// Stare !kanal przyjmowało dowolny tekst (np. "bot"); takie wartości
+		// czyścimy, żeby cron nie wysyłał w próżnię. Trzeba ustawić kanał na nowo.
+		for _, ch := range []*string{&gc.ChannelID, &gc.GemChannelID} {
+			if *ch != "" && !isSnowflake(*ch) {
+				log.Printf("Serwer %q: nieprawidłowe ID kanału %q, ustaw je ponownie przez !kanal / !gemsubscribe", key, *ch)
+				*ch = ""
+				migrated = true
+			}
+		}
+	}
+	if migrated {
+		if err := js.save(); err != nil {
+			return nil, err
+		}
+	}
+	if err := js.claimDefault(guildOf); err != nil {
+		return nil, err
+	}
+	return js, nil
+}
+
+// claimDefault przypisuje konfigurację spod defaultGuildKey serwerowi z
+// DEFAULT_GUILD_ID albo temu, do którego należy jej kanał. Gdy się nie da,
+// konfiguracja czeka dalej - nie oddajemy jej pierwszemu lepszemu serwerowi.
+func (js *jsonStore) claimDefault(guildOf func(channelID string) (string, error)) error {
+	gc, ok := js.config.Guilds[defaultGuildKey]
+	if !ok {
+		return nil
+	}
+	owner := os.Getenv("DEFAULT_GUILD_ID")
+	for _, ch := range []string{gc.ChannelID, gc.GemChannelID} {
+		if owner != "" || ch == "" || guildOf == nil {
+			continue
+		}
+		id, err := guildOf(ch)
+		if err != nil {
+			log.Printf("Nie udało się ustalić serwera kanału %s: %v", ch, err)
+			continue
+		}
+		owner = id
+	}
+	if owner == "" {
+		log.Printf("⚠️ UWAGA: stara konfiguracja (cytatów: %d) nie ma przypisanego serwera i bot jej nie używa. Ustaw DEFAULT_GUILD_ID i uruchom bota ponownie.", len(gc.Quotes))
+		return nil
+	}
+	if _, taken := js.config.Guilds[owner]; taken {
+		log.Printf("⚠️ UWAGA: serwer %s ma już własną konfigurację, stara (cytatów: %d) zostaje pod kluczem %q w %s. Połącz je ręcznie.", owner, len(gc.Quotes), defaultGuildKey, js.path)
+		return nil
+	}
+	js.config.Guilds[owner] = gc
+	delete(js.config.Guilds, defaultGuildKey)
+	log.Printf("Stara konfiguracja przypisana do serwera %s", owner)
+	return js.save()
+}
+
+// assignQuoteIDs jednorazowo zamienia pozycje z listy na stałe ID: cytat,
+// który był n-ty na !lista, dostaje ID n.
+func assignQuoteIDs(gc *GuildConfig) {
+	next := 1
+	for _, q := range gc.Quotes {
+		next = max(next, q.ID+1)
+	}
+	for i := range gc.Quotes {
+		if gc.Quotes[i].ID == 0 {
+			gc.Quotes[i].ID = i + 1
+			next = max(next, i+2)
+		}
+	}
+	gc.NextQuoteID = next
+}
+
+// save zapisuje stan do pliku tymczasowego i podmienia go przez rename,
+// więc przerwany zapis nigdy nie zostawia uciętego config.json.
+// Wywołujący musi trzymać js.mu.
+func (js *jsonStore) save() error {
+	data, err := json.MarshalIndent(js.config, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(js.path), filepath.Base(js.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), js.path)
+}
+
+// guild zwraca konfigurację serwera. Nieznany serwer dostaje niezapisaną
+// konfigurację startową, więc sam odczyt niczego nie tworzy ani nie zapisuje.
+// Wywołujący musi trzymać js.mu.
+func (js *jsonStore) guild(guildID string) *GuildConfig {
+	if gc, ok := js.config.Guilds[guildID]; ok {
+		return gc
+	}
+	return newGuildConfig()
+}
+
+func newGuildConfig() *GuildConfig {
+	gc := &GuildConfig{Quotes: newDefaultQuotes()}
+	gc.NextQuoteID = len(gc.Quotes) + 1
+	return gc
+}
+
+// errUnchanged zwrócone z fn w change oznacza, że nie ma czego zapisywać.
+var errUnchanged = errors.New("bez zmian")
+
+// change zmienia konfigurację i zapisuje plik. Gdy fn zwróci błąd albo zapis
+// się nie uda, stan w pamięci wraca do ostatniego zapisanego, żeby nie
+// rozjechał się z tym na dysku.
+func (js *jsonStore) change(fn func() error) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	snapshot, err := json.Marshal(js.config)
+	if err != nil {
+		return err
+	}
+	if err = fn(); errors.Is(err, errUnchanged) {
+		return nil
+	}
+	if err == nil {
+		err = js.save()
+	}
+	if err != nil {
+		var restored Config
+		if jerr := json.Unmarshal(snapshot, &restored); jerr == nil {
+			js.config = restored
+		}
+	}
+	return err
+}
+
+// update zmienia konfigurację jednego serwera przez change. Nieznany serwer
+// zakładamy dopiero tu.
+func (js *jsonStore) update(guildID string, fn func(gc *GuildConfig) error) error {
+	return js.change(func() error {
+		gc, ok := js.config.Guilds[guildID]
+		if !ok {
+			gc = newGuildConfig()
+			js.config.Guilds[guildID] = gc
+		}
+		return fn(gc)
+	})
+}
+
+func (js *jsonStore) Guilds() ([]string, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	ids := make([]string, 0, len(js.config.Guilds))
+	for id := range js.config.Guilds {
+		if id != defaultGuildKey {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (js *jsonStore) Settings(guildID string) (GuildSettings, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	gc := js.guild(guildID)
+	return gc.GuildSettings, nil
+}
+
+func (js *jsonStore) UpdateSettings(guildID string, fn func(*GuildSettings)) error {
+	return js.update(guildID, func(gc *GuildConfig) error {
+		fn(&gc.GuildSettings)
+		return nil
+	})
+}
+
+func (js *jsonStore) Quotes(guildID string) ([]Quote, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	gc := js.guild(guildID)
+	return append([]Quote(nil), gc.Quotes...), nil
+}
+
+func (js *jsonStore) AddQuote(guildID string, q Quote) (Quote, error) {
+	err := js.update(guildID, func(gc *GuildConfig) error {
+		q.ID = gc.NextQuoteID
+		gc.NextQuoteID++
+		gc.Quotes = append(gc.Quotes, q)
+		return nil
+	})
+	return q, err
+}
+
+func (js *jsonStore) ImportQuotes(guildID string, quotes []Quote, replace bool, deletedBy string) ([]Quote, int, error) {
+	added := make([]Quote, 0, len(quotes))
+	trashed := 0
+	err := js.update(guildID, func(gc *GuildConfig) error {
+		if replace {
+			now := time.Now()
+			for _, q := range gc.Quotes {
+				q.DeletedAt, q.DeletedBy = now, deletedBy
+				gc.Trash = append(gc.Trash, q)
+			}
+			trashed = len(gc.Quotes)
+			gc.Quotes = nil
+		}
+		for _, q := range quotes {
+			q.ID = gc.NextQuoteID
+			gc.NextQuoteID++
+			gc.Quotes = append(gc.Quotes, q)
+			added = append(added, q)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return added, trashed, nil
+}
+
+func (js *jsonStore) AddPending(guildID string, q Quote) (Quote, error) {
+	err := js.update(guildID, func(gc *GuildConfig) error {
+		q.ID = max(gc.NextPendingID, 1)
+		gc.NextPendingID = q.ID + 1
+		gc.Pending = append(gc.Pending, q)
+		return nil
+	})
+	return q, err
+}
+
+func (js *jsonStore) Pending(guildID string) ([]Quote, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	gc := js.guild(guildID)
+	return append([]Quote(nil), gc.Pending...), nil
+}
+
+func (js *jsonStore) ApprovePending(guildID string, id int) (Quote, error) {
+	var approved Quote
+	err := js.update(guildID, func(gc *GuildConfig) error {
+		i := quoteIndex(gc.Pending, id)
+		if i < 0 {
+			return errPendingNotFound
+		}
+		approved = gc.Pending[i]
+		gc.Pending = append(gc.Pending[:i], gc.Pending[i+1:]...)
+		approved.ID = gc.NextQuoteID
+		gc.NextQuoteID++
+		gc.Quotes = append(gc.Quotes, approved)
+		return nil
+	})
+	return approved, err
+}
+
+func (js *jsonStore) RejectPending(guildID string, id int) (Quote, error) {
+	var rejected Quote
+	err := js.update(guildID, func(gc *GuildConfig) error {
+		i := quoteIndex(gc.Pending, id)
+		if i < 0 {
+			return errPendingNotFound
+		}
+		rejected = gc.Pending[i]
+		gc.Pending = append(gc.Pending[:i], gc.Pending[i+1:]...)
+		return nil
+	})
+	return rejected, err
+}
+
+func (js *jsonStore) DeleteQuote(guildID string, id int, deletedBy string) error {
+	return js.update(guildID, func(gc *GuildConfig) error {
+		i := quoteIndex(gc.Quotes, id)
+		if i < 0 {
+			return errQuoteNotFound
+		}
+		q := gc.Quotes[i]
+		q.DeletedAt = time.Now()
+		q.DeletedBy = deletedBy
+		gc.Quotes = append(gc.Quotes[:i], gc.Quotes[i+1:]...)
+		gc.Trash = append(gc.Trash, q)
+		return nil
+	})
+}
+
+func (js *jsonStore) Trash(guildID string) ([]Quote, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	gc := js.guild(guildID)
+	trash := make([]Quote, 0, len(gc.Trash))
+	for i := len(gc.Trash) - 1; i >= 0; i-- {
+		trash = append(trash, gc.Trash[i])
+	}
+	return trash, nil
+}
+
+func (js *jsonStore) RestoreQuote(guildID string, id int) (Quote, error) {
+	var restored Quote
+	err := js.update(guildID, func(gc *GuildConfig) error {
+		i := quoteIndex(gc.Trash, id)
+		if i < 0 {
+			return errQuoteNotFound
+		}
+		restored = gc.Trash[i]
+		restored.DeletedAt, restored.DeletedBy = time.Time{}, ""
+		gc.Trash = append(gc.Trash[:i], gc.Trash[i+1:]...)
+		// Przywrócony cytat wraca na swoje miejsce według ID.
+		at := sort.Search(len(gc.Quotes), func(j int) bool { return gc.Quotes[j].ID > id })
+		gc.Quotes = slices.Insert(gc.Quotes, at, restored)
+		return nil
+	})
+	return restored, err
+}
+
+func (js *jsonStore) PurgeTrash(before time.Time) (int, error) {
+	purged := 0
+	err := js.change(func() error {
+		for _, gc := range js.config.Guilds {
+			kept := gc.Trash[:0]
+			for _, q := range gc.Trash {
+				if q.DeletedAt.Before(before) {
+					delete(gc.Revisions, q.ID)
+					purged++
+					continue
+				}
+				kept = append(kept, q)
+			}
+			gc.Trash = kept
+		}
+		if purged == 0 {
+			return errUnchanged
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+func (js *jsonStore) Quote(guildID string, id int) (Quote, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	gc := js.guild(guildID)
+	i := quoteIndex(gc.Quotes, id)
+	if i < 0 {
+		return Quote{}, errQuoteNotFound
+	}
+	return gc.Quotes[i], nil
+}
+
+func (js *jsonStore) EditQuote(guildID string, id int, editorID string, fn func(*Quote)) (Quote, error) {
+	var edited Quote
+	err := js.update(guildID, func(gc *GuildConfig) error {
+		i := quoteIndex(gc.Quotes, id)
+		if i < 0 {
+			return errQuoteNotFound
+		}
+		if gc.Revisions == nil {
+			gc.Revisions = map[int][]QuoteRevision{}
+		}
+		gc.Revisions[id] = append(gc.Revisions[id], revisionOf(gc.Quotes[i], editorID))
+		edited = applyEdit(gc.Quotes[i], fn)
+		gc.Quotes[i] = edited
+		return nil
+	})
+	return edited, err
+}
+
+func (js *jsonStore) QuoteRevisions(guildID string, id int) ([]QuoteRevision, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	gc := js.guild(guildID)
+	if quoteIndex(gc.Quotes, id) < 0 {
+		return nil, errQuoteNotFound
+	}
+	return append([]QuoteRevision(nil), gc.Revisions[id]...), nil
+}
+
+func quoteIndex(quotes []Quote, id int) int {
+	for i, q := range quotes {
+		if q.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (js *jsonStore) UpdateDailyBag(guildID string, fn func(bag []int) []int) error {
+	return js.update(guildID, func(gc *GuildConfig) error {
+		gc.DailyBag = fn(append([]int(nil), gc.DailyBag...))
+		return nil
+	})
+}
+
+func (js *jsonStore) RecordPost(guildID, messageID string, quoteID int) error {
+	return js.update(guildID, func(gc *GuildConfig) error {
+		if gc.Posts == nil {
+			gc.Posts = map[string]*QuotePost{}
+		}
+		now := time.Now()
+		for id, post := range gc.Posts {
+			if len(post.Up) == 0 && len(post.Down) == 0 && now.Sub(post.PostedAt) > unvotedPostTTL {
+				delete(gc.Posts, id)
+			}
+		}
+		gc.Posts[messageID] = &QuotePost{QuoteID: quoteID, PostedAt: now}
+		return nil
+	})
+}
+
+func (js *jsonStore) SetVote(guildID, messageID, userID string, value int, on bool) error {
+	// Większość reakcji trafia pod zwykłe wiadomości; nie robimy dla nich
+	// migawki całej konfiguracji w update.
+	js.mu.Lock()
+	_, ok := js.guild(guildID).Posts[messageID]
+	js.mu.Unlock()
+	if !ok {
+		return errPostNotFound
+	}
+	return js.update(guildID, func(gc *GuildConfig) error {
+		post, ok := gc.Posts[messageID]
+		if !ok {
+			return errPostNotFound
+		}
+		voters := &post.Up
+		if value < 0 {
+			voters = &post.Down
+		}
+		i := slices.Index(*voters, userID)
+		switch {
+		case on && i < 0:
+			*voters = append(*voters, userID)
+		case !on && i >= 0:
+			*voters = slices.Delete(*voters, i, i+1)
+		}
+		return nil
+	})
+}
+
+func (js *jsonStore) Scores(guildID string) (map[int]int, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	gc := js.guild(guildID)
+	scores := map[int]int{}
+	for _, post := range gc.Posts {
+		if len(post.Up) == 0 && len(post.Down) == 0 {
+			continue
+		}
+		scores[post.QuoteID] += len(post.Up) - len(post.Down)
+	}
+	return scores, nil
+}
+
+func (js *jsonStore) RecordDaily(guildID string, entry DailyEntry) error {
+	return js.update(guildID, func(gc *GuildConfig) error {
+		i, found := slices.BinarySearchFunc(gc.History, entry.Date, func(e DailyEntry, date string) int {
+			return strings.Compare(e.Date, date)
+		})
+		if found {
+			gc.History[i] = entry
+		} else {
+			gc.History = slices.Insert(gc.History, i, entry)
+		}
+		return nil
+	})
+}
+
+func (js *jsonStore) DailyHistory(guildID string, limit int) ([]DailyEntry, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	gc := js.guild(guildID)
+	recent := gc.History[max(0, len(gc.History)-limit):]
+	entries := make([]DailyEntry, len(recent))
+	for i, e := range recent {
+		entries[len(recent)-1-i] = e
+	}
+	return entries, nil
+}
+
+func (js *jsonStore) DailyOn(guildID, date string) (DailyEntry, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	gc := js.guild(guildID)
+	for _, e := range gc.History {
+		if e.Date == date {
+			return e, nil
+		}
+	}
+	return DailyEntry{}, errDailyNotFound
+}
+
+func (js *jsonStore) CommandAccess(guildID string) (map[string]AccessRule, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	gc := js.guild(guildID)
+	access := make(map[string]AccessRule, len(gc.Access))
+	for command, rule := range gc.Access {
+		rule.Roles = append([]string(nil), rule.Roles...)
+		access[command] = rule
+	}
+	return access, nil
+}
+
+func (js *jsonStore) SetCommandAccess(guildID, command string, rule *AccessRule) error {
+	return js.update(guildID, func(gc *GuildConfig) error {
+		if rule == nil {
+			delete(gc.Access, command)
+			return nil
+		}
+		if gc.Access == nil {
+			gc.Access = map[string]AccessRule{}
+		}
+		gc.Access[command] = *rule
+		return nil
+	})
+}
+
+func (js *jsonStore) GemSubscribers(guildID string) ([]string, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	gc := js.guild(guildID)
+	return append([]string(nil), gc.GemSubscribers...), nil
+}
+
+func (js *jsonStore) AddGemSubscriber(guildID, userID string) (bool, error) {
+	added := false
+	err := js.update(guildID, func(gc *GuildConfig) error {
+		for _, id := range gc.GemSubscribers {
+			if id == userID {
+				return nil
+			}
+		}
+		gc.GemSubscribers = append(gc.GemSubscribers, userID)
+		added = true
+		return nil
+	})
+	return added, err
+}
+
+func (js *jsonStore) Close() error {
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeLegacyConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	legacy := `{"quotes": ["pierwsza", "druga"], "channel_id": "123456789012345678", "gem_channel_id": "bot", "gem_subscribers": ["u1"]}`
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestJSONLegacyMigration(t *testing.T) {
+	t.Setenv("DEFAULT_GUILD_ID", "")
+	path := writeLegacyConfig(t)
+	guildOf := func(channelID string) (string, error) {
+		if channelID == "123456789012345678" {
+			return "555", nil
+		}
+		return "", errors.New("nieznany kanał")
+	}
+	js, err := openJSONStore(path, guildOf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if guilds, _ := js.Guilds(); !slices.Equal(guilds, []string{"555"}) {
+		t.Fatalf("Guilds = %v, chcę [555]", guilds)
+	}
+	quotes, _ := js.Quotes("555")
+	if !slices.Equal(quoteIDs(quotes), []int{1, 2}) || quotes[0].Text != "pierwsza" {
+		t.Errorf("Quotes = %+v", quotes)
+	}
+	settings, _ := js.Settings("555")
+	if settings.ChannelID != "123456789012345678" || settings.GemChannelID != "" {
+		t.Errorf("Settings = %+v, chcę kanału z pliku i wyczyszczonego \"bot\"", settings)
+	}
+	if subs, _ := js.GemSubscribers("555"); !slices.Equal(subs, []string{"u1"}) {
+		t.Errorf("GemSubscribers = %v", subs)
+	}
+
+	// Po ponownym otwarciu nic się nie przesuwa.
+	js, err = openJSONStore(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q, _ := js.AddQuote("555", Quote{Text: "trzecia"}); q.ID != 3 {
+		t.Errorf("ID nowego cytatu = %d, chcę 3", q.ID)
+	}
+}
+
+func TestJSONLegacyMigrationWaitsForOwner(t *testing.T) {
+	t.Setenv("DEFAULT_GUILD_ID", "")
+	path := writeLegacyConfig(t)
+	js, err := openJSONStore(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Bez właściciela pierwszy serwer, który się odezwie, nie dostaje starych cytatów.
+	js.AddQuote("777", Quote{Text: "obca"})
+	if quotes, _ := js.Quotes("777"); len(quotes) != len(defaultQuotes)+1 {
+		t.Errorf("serwer 777 ma %d cytatów, chcę domyślnych i swojego", len(quotes))
+	}
+	if guilds, _ := js.Guilds(); !slices.Equal(guilds, []string{"777"}) {
+		t.Errorf("Guilds = %v", guilds)
+	}
+
+	t.Setenv("DEFAULT_GUILD_ID", "555")
+	js, err = openJSONStore(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quotes, _ := js.Quotes("555"); len(quotes) != 2 || quotes[1].Text != "druga" {
+		t.Errorf("Quotes(555) = %+v", quotes)
+	}
+}
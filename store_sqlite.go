@@ -0,0 +1,952 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteMigrations są wykonywane po kolei; numer ostatniej zastosowanej
+// migracji trzymamy w PRAGMA user_version. Nowe zmiany schematu dopisujemy
+// na końcu, nigdy nie edytujemy istniejących.
+var sqliteMigrations = []string{
+	`CREATE TABLE guilds (
+		guild_id       TEXT PRIMARY KEY,
+		channel_id     TEXT NOT NULL DEFAULT '',
+		gem_channel_id TEXT NOT NULL DEFAULT ''
+	);
+	CREATE TABLE quotes (
+		id       INTEGER PRIMARY KEY AUTOINCREMENT,
+		guild_id TEXT NOT NULL REFERENCES guilds(guild_id),
+		text     TEXT NOT NULL
+	);
+	CREATE INDEX quotes_guild ON quotes(guild_id);
+	CREATE TABLE gem_subscribers (
+		guild_id TEXT NOT NULL REFERENCES guilds(guild_id),
+		user_id  TEXT NOT NULL,
+		PRIMARY KEY (guild_id, user_id)
+	);`,
+	`ALTER TABLE quotes ADD COLUMN author TEXT NOT NULL DEFAULT '';
+	ALTER TABLE quotes ADD COLUMN submitter_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE quotes ADD COLUMN created_at INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE quotes ADD COLUMN source TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE guilds ADD COLUMN daily_tag TEXT NOT NULL DEFAULT '';
+	CREATE TABLE quote_tags (
+		quote_id INTEGER NOT NULL REFERENCES quotes(id) ON DELETE CASCADE,
+		tag      TEXT NOT NULL,
+		PRIMARY KEY (quote_id, tag)
+	);
+	CREATE INDEX quote_tags_tag ON quote_tags(tag);`,
+	// number to widoczne dla użytkowników ID cytatu w obrębie serwera; id
+	// zostaje wewnętrznym kluczem. Dotychczasowe pozycje z !lista stają się ID.
+	`ALTER TABLE quotes ADD COLUMN number INTEGER NOT NULL DEFAULT 0;
+	UPDATE quotes SET number = (
+		SELECT COUNT(*) FROM quotes q2 WHERE q2.guild_id = quotes.guild_id AND q2.id <= quotes.id);
+	CREATE UNIQUE INDEX quotes_guild_number ON quotes(guild_id, number);
+	ALTER TABLE guilds ADD COLUMN next_quote_id INTEGER NOT NULL DEFAULT 1;
+	UPDATE guilds SET next_quote_id = 1 + (
+		SELECT COALESCE(MAX(number), 0) FROM quotes WHERE quotes.guild_id = guilds.guild_id);`,
+	`CREATE TABLE quote_revisions (
+		quote_id  INTEGER NOT NULL REFERENCES quotes(id) ON DELETE CASCADE,
+		text      TEXT NOT NULL,
+		author    TEXT NOT NULL DEFAULT '',
+		source    TEXT NOT NULL DEFAULT '',
+		tags      TEXT NOT NULL DEFAULT '',
+		edited_by TEXT NOT NULL DEFAULT '',
+		edited_at INTEGER NOT NULL
+	);
+	CREATE INDEX quote_revisions_quote ON quote_revisions(quote_id);`,
+	`ALTER TABLE quotes ADD COLUMN deleted_at INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE quotes ADD COLUMN deleted_by TEXT NOT NULL DEFAULT '';`,
+	`CREATE TABLE daily_bag (
+		guild_id     TEXT NOT NULL REFERENCES guilds(guild_id),
+		position     INTEGER NOT NULL,
+		quote_number INTEGER NOT NULL,
+		PRIMARY KEY (guild_id, position)
+	);`,
+	`ALTER TABLE guilds ADD COLUMN weighted_random INTEGER NOT NULL DEFAULT 0;
+	CREATE TABLE quote_posts (
+		message_id   TEXT PRIMARY KEY,
+		guild_id     TEXT NOT NULL REFERENCES guilds(guild_id),
+		quote_number INTEGER NOT NULL,
+		posted_at    INTEGER NOT NULL
+	);
+	CREATE TABLE quote_votes (
+		message_id TEXT NOT NULL REFERENCES quote_posts(message_id) ON DELETE CASCADE,
+		user_id    TEXT NOT NULL,
+		value      INTEGER NOT NULL,
+		PRIMARY KEY (message_id, user_id, value)
+	);`,
+	`CREATE TABLE daily_history (
+		guild_id     TEXT NOT NULL REFERENCES guilds(guild_id),
+		date         TEXT NOT NULL,
+		channel_id   TEXT NOT NULL,
+		quote_number INTEGER NOT NULL,
+		message_id   TEXT NOT NULL,
+		posted_at    INTEGER NOT NULL,
+		PRIMARY KEY (guild_id, date)
+	);`,
+	`ALTER TABLE guilds ADD COLUMN mod_channel_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE guilds ADD COLUMN next_pending_id INTEGER NOT NULL DEFAULT 1;
+	CREATE TABLE pending_quotes (
+		guild_id     TEXT NOT NULL REFERENCES guilds(guild_id),
+		number       INTEGER NOT NULL,
+		text         TEXT NOT NULL,
+		author       TEXT NOT NULL DEFAULT '',
+		submitter_id TEXT NOT NULL DEFAULT '',
+		created_at   INTEGER NOT NULL DEFAULT 0,
+		source       TEXT NOT NULL DEFAULT '',
+		tags         TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (guild_id, number)
+	);`,
+	`CREATE TABLE command_access (
+		guild_id    TEXT NOT NULL REFERENCES guilds(guild_id),
+		command     TEXT NOT NULL,
+		everyone    INTEGER NOT NULL DEFAULT 0,
+		roles       TEXT NOT NULL DEFAULT '',
+		permissions INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (guild_id, command)
+	);`,
+	`ALTER TABLE guilds ADD COLUMN plain_text INTEGER NOT NULL DEFAULT 0;`,
+	`ALTER TABLE guilds ADD COLUMN prefix TEXT NOT NULL DEFAULT '';`,
+}
+
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func openSQLiteStore(path string) (*sqliteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite i tak serializuje zapisy; jedno połączenie oszczędza nam SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+	ss := &sqliteStore{db: db}
+	if err := ss.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return ss, nil
+}
+
+func (ss *sqliteStore) migrate() error {
+	var version int
+	if err := ss.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := ss.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(sqliteMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migracja %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureGuild zakłada wiersz serwera i przy pierwszym razie dodaje domyślne cytaty.
+func (ss *sqliteStore) ensureGuild(guildID string) error {
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec("INSERT OR IGNORE INTO guilds (guild_id) VALUES (?)", guildID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil
+	}
+	for _, q := range newDefaultQuotes() {
+		if _, err := tx.Exec("INSERT INTO quotes (guild_id, number, text) VALUES (?, ?, ?)", guildID, q.ID, q.Text); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("UPDATE guilds SET next_quote_id = ? WHERE guild_id = ?", len(defaultQuotes)+1, guildID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// knownGuild mówi, czy serwer ma już wiersz. Odczyty nie zakładają serwera
+// (to robi ensureGuild przy zapisie), tylko widzą jego domyślne cytaty.
+func (ss *sqliteStore) knownGuild(guildID string) (bool, error) {
+	var n int
+	err := ss.db.QueryRow("SELECT COUNT(*) FROM guilds WHERE guild_id = ?", guildID).Scan(&n)
+	return n > 0, err
+}
+
+// defaultQuote szuka cytatu wśród domyślnych, które widzi nieznany serwer.
+func defaultQuote(id int) (Quote, error) {
+	quotes := newDefaultQuotes()
+	if i := quoteIndex(quotes, id); i >= 0 {
+		return quotes[i], nil
+	}
+	return Quote{}, errQuoteNotFound
+}
+
+func (ss *sqliteStore) Guilds() ([]string, error) {
+	rows, err := ss.db.Query("SELECT guild_id FROM guilds ORDER BY guild_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Kolumny tabeli guilds odpowiadające polom GuildSettings, w tej samej
+// kolejności co w settingsFields.
+var settingsColumns = []string{"channel_id", "gem_channel_id", "daily_tag", "weighted_random", "mod_channel_id", "plain_text", "prefix"}
+
+func settingsFields(gs *GuildSettings) []any {
+	return []any{&gs.ChannelID, &gs.GemChannelID, &gs.DailyTag, &gs.WeightedRandom, &gs.ModChannelID, &gs.PlainText, &gs.Prefix}
+}
+
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func loadSettings(q queryRower, guildID string) (GuildSettings, error) {
+	var gs GuildSettings
+	err := q.QueryRow("SELECT "+strings.Join(settingsColumns, ", ")+" FROM guilds WHERE guild_id = ?", guildID).
+		Scan(settingsFields(&gs)...)
+	return gs, err
+}
+
+func (ss *sqliteStore) Settings(guildID string) (GuildSettings, error) {
+	gs, err := loadSettings(ss.db, guildID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return GuildSettings{}, nil
+	}
+	return gs, err
+}
+
+// UpdateSettings czyta i zapisuje ustawienia w jednej transakcji, żeby dwie
+// równoległe zmiany różnych pól nie nadpisały się nawzajem.
+func (ss *sqliteStore) UpdateSettings(guildID string, fn func(*GuildSettings)) error {
+	if err := ss.ensureGuild(guildID); err != nil {
+		return err
+	}
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	gs, err := loadSettings(tx, guildID)
+	if err != nil {
+		return err
+	}
+	fn(&gs)
+	// database/sql sam wyłuskuje wskaźniki z settingsFields.
+	_, err = tx.Exec("UPDATE guilds SET "+strings.Join(settingsColumns, " = ?, ")+" = ? WHERE guild_id = ?",
+		append(settingsFields(&gs), guildID)...)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (ss *sqliteStore) Quotes(guildID string) ([]Quote, error) {
+	if known, err := ss.knownGuild(guildID); err != nil || !known {
+		return newDefaultQuotes(), err
+	}
+	return queryQuotes(ss.db, "SELECT "+quoteColumns+" FROM quotes q WHERE guild_id = ? AND deleted_at = 0 ORDER BY number", guildID)
+}
+
+func queryQuotes(db *sql.DB, query string, args ...any) ([]Quote, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var quotes []Quote
+	for rows.Next() {
+		q, err := scanQuote(rows)
+		if err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, q)
+	}
+	return quotes, rows.Err()
+}
+
+// quoteColumns to kolumny czytane przez scanQuote; tabela quotes musi mieć alias q.
+const quoteColumns = `number, text, author, submitter_id, created_at, source, deleted_at, deleted_by,
+	(SELECT group_concat(tag, ' ') FROM quote_tags t WHERE t.quote_id = q.id)`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanQuote czyta kolumny quoteColumns; extra to cele dla kolumn wybranych
+// przed nimi.
+func scanQuote(sc scanner, extra ...any) (Quote, error) {
+	var q Quote
+	var created, deleted int64
+	var tags sql.NullString
+	dest := append(extra, &q.ID, &q.Text, &q.Author, &q.SubmitterID, &created, &q.Source, &deleted, &q.DeletedBy, &tags)
+	if err := sc.Scan(dest...); err != nil {
+		return q, err
+	}
+	q.CreatedAt = unixTime(created)
+	q.DeletedAt = unixTime(deleted)
+	q.Tags = strings.Fields(tags.String)
+	return q, nil
+}
+
+// quoteRow zwraca wewnętrzne id wiersza i cytat (spoza kosza) o danym ID serwera.
+func quoteRow(q queryRower, guildID string, id int) (int64, Quote, error) {
+	var rowID int64
+	row := q.QueryRow("SELECT id, "+quoteColumns+" FROM quotes q WHERE guild_id = ? AND number = ? AND deleted_at = 0", guildID, id)
+	quote, err := scanQuote(row, &rowID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, quote, errQuoteNotFound
+	}
+	return rowID, quote, err
+}
+
+func (ss *sqliteStore) Quote(guildID string, id int) (Quote, error) {
+	if known, err := ss.knownGuild(guildID); err != nil || !known {
+		if err != nil {
+			return Quote{}, err
+		}
+		return defaultQuote(id)
+	}
+	_, q, err := quoteRow(ss.db, guildID, id)
+	return q, err
+}
+
+func (ss *sqliteStore) EditQuote(guildID string, id int, editorID string, fn func(*Quote)) (Quote, error) {
+	if err := ss.ensureGuild(guildID); err != nil {
+		return Quote{}, err
+	}
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return Quote{}, err
+	}
+	defer tx.Rollback()
+	rowID, old, err := quoteRow(tx, guildID, id)
+	if err != nil {
+		return Quote{}, err
+	}
+	rev := revisionOf(old, editorID)
+	_, err = tx.Exec(`INSERT INTO quote_revisions (quote_id, text, author, source, tags, edited_by, edited_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`, rowID, rev.Text, rev.Author, rev.Source, strings.Join(rev.Tags, " "), rev.EditedBy, rev.EditedAt.Unix())
+	if err != nil {
+		return Quote{}, err
+	}
+	edited := applyEdit(old, fn)
+	_, err = tx.Exec("UPDATE quotes SET text = ?, author = ?, source = ? WHERE id = ?",
+		edited.Text, edited.Author, edited.Source, rowID)
+	if err != nil {
+		return Quote{}, err
+	}
+	if _, err := tx.Exec("DELETE FROM quote_tags WHERE quote_id = ?", rowID); err != nil {
+		return Quote{}, err
+	}
+	if err := insertTags(tx, rowID, edited.Tags); err != nil {
+		return Quote{}, err
+	}
+	return edited, tx.Commit()
+}
+
+func (ss *sqliteStore) QuoteRevisions(guildID string, id int) ([]QuoteRevision, error) {
+	if known, err := ss.knownGuild(guildID); err != nil || !known {
+		if err != nil {
+			return nil, err
+		}
+		_, err := defaultQuote(id)
+		return nil, err
+	}
+	rowID, _, err := quoteRow(ss.db, guildID, id)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := ss.db.Query(`SELECT text, author, source, tags, edited_by, edited_at
+		FROM quote_revisions WHERE quote_id = ? ORDER BY rowid`, rowID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var revs []QuoteRevision
+	for rows.Next() {
+		var rev QuoteRevision
+		var tags string
+		var edited int64
+		if err := rows.Scan(&rev.Text, &rev.Author, &rev.Source, &tags, &rev.EditedBy, &edited); err != nil {
+			return nil, err
+		}
+		rev.Tags = strings.Fields(tags)
+		rev.EditedAt = time.Unix(edited, 0)
+		revs = append(revs, rev)
+	}
+	return revs, rows.Err()
+}
+
+func (ss *sqliteStore) AddQuote(guildID string, q Quote) (Quote, error) {
+	if err := ss.ensureGuild(guildID); err != nil {
+		return q, err
+	}
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return q, err
+	}
+	defer tx.Rollback()
+	q, err = insertQuote(tx, guildID, q)
+	if err != nil {
+		return q, err
+	}
+	return q, tx.Commit()
+}
+
+// insertQuote nadaje cytatowi kolejne ID serwera i zapisuje go razem z tagami.
+func insertQuote(tx *sql.Tx, guildID string, q Quote) (Quote, error) {
+	if err := tx.QueryRow("SELECT next_quote_id FROM guilds WHERE guild_id = ?", guildID).Scan(&q.ID); err != nil {
+		return q, err
+	}
+	if _, err := tx.Exec("UPDATE guilds SET next_quote_id = next_quote_id + 1 WHERE guild_id = ?", guildID); err != nil {
+		return q, err
+	}
+	res, err := tx.Exec(`INSERT INTO quotes (guild_id, number, text, author, submitter_id, created_at, source)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`, guildID, q.ID, q.Text, q.Author, q.SubmitterID, unixSeconds(q.CreatedAt), q.Source)
+	if err != nil {
+		return q, err
+	}
+	rowID, err := res.LastInsertId()
+	if err != nil {
+		return q, err
+	}
+	return q, insertTags(tx, rowID, q.Tags)
+}
+
+func (ss *sqliteStore) ImportQuotes(guildID string, quotes []Quote, replace bool, deletedBy string) ([]Quote, int, error) {
+	if err := ss.ensureGuild(guildID); err != nil {
+		return nil, 0, err
+	}
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+	trashed := 0
+	if replace {
+		res, err := tx.Exec(`UPDATE quotes SET deleted_at = ?, deleted_by = ?
+			WHERE guild_id = ? AND deleted_at = 0`, time.Now().Unix(), deletedBy, guildID)
+		if err != nil {
+			return nil, 0, err
+		}
+		n, _ := res.RowsAffected()
+		trashed = int(n)
+	}
+	added := make([]Quote, 0, len(quotes))
+	for _, q := range quotes {
+		q, err = insertQuote(tx, guildID, q)
+		if err != nil {
+			return nil, 0, err
+		}
+		added = append(added, q)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, 0, err
+	}
+	return added, trashed, nil
+}
+
+func (ss *sqliteStore) AddPending(guildID string, q Quote) (Quote, error) {
+	if err := ss.ensureGuild(guildID); err != nil {
+		return q, err
+	}
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return q, err
+	}
+	defer tx.Rollback()
+	if err := tx.QueryRow("SELECT next_pending_id FROM guilds WHERE guild_id = ?", guildID).Scan(&q.ID); err != nil {
+		return q, err
+	}
+	if _, err := tx.Exec("UPDATE guilds SET next_pending_id = next_pending_id + 1 WHERE guild_id = ?", guildID); err != nil {
+		return q, err
+	}
+	_, err = tx.Exec(`INSERT INTO pending_quotes (guild_id, number, text, author, submitter_id, created_at, source, tags)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, guildID, q.ID, q.Text, q.Author, q.SubmitterID, unixSeconds(q.CreatedAt), q.Source, strings.Join(q.Tags, " "))
+	if err != nil {
+		return q, err
+	}
+	return q, tx.Commit()
+}
+
+const pendingColumns = "number, text, author, submitter_id, created_at, source, tags"
+
+func scanPending(sc scanner) (Quote, error) {
+	var q Quote
+	var created int64
+	var tags string
+	err := sc.Scan(&q.ID, &q.Text, &q.Author, &q.SubmitterID, &created, &q.Source, &tags)
+	q.CreatedAt = unixTime(created)
+	q.Tags = strings.Fields(tags)
+	return q, err
+}
+
+func (ss *sqliteStore) Pending(guildID string) ([]Quote, error) {
+	rows, err := ss.db.Query("SELECT "+pendingColumns+" FROM pending_quotes WHERE guild_id = ? ORDER BY number", guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var pending []Quote
+	for rows.Next() {
+		q, err := scanPending(rows)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, q)
+	}
+	return pending, rows.Err()
+}
+
+// takePending usuwa zgłoszenie w ramach tx i zwraca je.
+func takePending(tx *sql.Tx, guildID string, id int) (Quote, error) {
+	q, err := scanPending(tx.QueryRow("SELECT "+pendingColumns+" FROM pending_quotes WHERE guild_id = ? AND number = ?", guildID, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return q, errPendingNotFound
+	}
+	if err != nil {
+		return q, err
+	}
+	_, err = tx.Exec("DELETE FROM pending_quotes WHERE guild_id = ? AND number = ?", guildID, id)
+	return q, err
+}
+
+func (ss *sqliteStore) ApprovePending(guildID string, id int) (Quote, error) {
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return Quote{}, err
+	}
+	defer tx.Rollback()
+	q, err := takePending(tx, guildID, id)
+	if err != nil {
+		return q, err
+	}
+	if q, err = insertQuote(tx, guildID, q); err != nil {
+		return q, err
+	}
+	return q, tx.Commit()
+}
+
+func (ss *sqliteStore) RejectPending(guildID string, id int) (Quote, error) {
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return Quote{}, err
+	}
+	defer tx.Rollback()
+	q, err := takePending(tx, guildID, id)
+	if err != nil {
+		return q, err
+	}
+	return q, tx.Commit()
+}
+
+func insertTags(tx *sql.Tx, quoteID int64, tags []string) error {
+	for _, tag := range tags {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO quote_tags (quote_id, tag) VALUES (?, ?)", quoteID, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ss *sqliteStore) DeleteQuote(guildID string, id int, deletedBy string) error {
+	if err := ss.ensureGuild(guildID); err != nil {
+		return err
+	}
+	res, err := ss.db.Exec(`UPDATE quotes SET deleted_at = ?, deleted_by = ?
+		WHERE guild_id = ? AND number = ? AND deleted_at = 0`, time.Now().Unix(), deletedBy, guildID, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errQuoteNotFound
+	}
+	return nil
+}
+
+func (ss *sqliteStore) Trash(guildID string) ([]Quote, error) {
+	return queryQuotes(ss.db, "SELECT "+quoteColumns+" FROM quotes q WHERE guild_id = ? AND deleted_at <> 0 ORDER BY deleted_at DESC, number", guildID)
+}
+
+func (ss *sqliteStore) RestoreQuote(guildID string, id int) (Quote, error) {
+	if err := ss.ensureGuild(guildID); err != nil {
+		return Quote{}, err
+	}
+	res, err := ss.db.Exec(`UPDATE quotes SET deleted_at = 0, deleted_by = ''
+		WHERE guild_id = ? AND number = ? AND deleted_at <> 0`, guildID, id)
+	if err != nil {
+		return Quote{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return Quote{}, errQuoteNotFound
+	}
+	return ss.Quote(guildID, id)
+}
+
+func (ss *sqliteStore) PurgeTrash(before time.Time) (int, error) {
+	// Tagi i wersje znikają razem z cytatem dzięki ON DELETE CASCADE.
+	res, err := ss.db.Exec("DELETE FROM quotes WHERE deleted_at <> 0 AND deleted_at < ?", before.Unix())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (ss *sqliteStore) UpdateDailyBag(guildID string, fn func(bag []int) []int) error {
+	if err := ss.ensureGuild(guildID); err != nil {
+		return err
+	}
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	rows, err := tx.Query("SELECT quote_number FROM daily_bag WHERE guild_id = ? ORDER BY position", guildID)
+	if err != nil {
+		return err
+	}
+	var bag []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		bag = append(bag, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	bag = fn(bag)
+	if _, err := tx.Exec("DELETE FROM daily_bag WHERE guild_id = ?", guildID); err != nil {
+		return err
+	}
+	for i, id := range bag {
+		if _, err := tx.Exec("INSERT INTO daily_bag (guild_id, position, quote_number) VALUES (?, ?, ?)", guildID, i, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (ss *sqliteStore) RecordPost(guildID, messageID string, quoteID int) error {
+	if err := ss.ensureGuild(guildID); err != nil {
+		return err
+	}
+	now := time.Now()
+	_, err := ss.db.Exec(`DELETE FROM quote_posts WHERE guild_id = ? AND posted_at < ?
+		AND message_id NOT IN (SELECT message_id FROM quote_votes)`, guildID, now.Add(-unvotedPostTTL).Unix())
+	if err != nil {
+		return err
+	}
+	_, err = ss.db.Exec("INSERT OR REPLACE INTO quote_posts (message_id, guild_id, quote_number, posted_at) VALUES (?, ?, ?, ?)",
+		messageID, guildID, quoteID, now.Unix())
+	return err
+}
+
+func (ss *sqliteStore) SetVote(guildID, messageID, userID string, value int, on bool) error {
+	var exists int
+	err := ss.db.QueryRow("SELECT 1 FROM quote_posts WHERE message_id = ? AND guild_id = ?", messageID, guildID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errPostNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if on {
+		_, err = ss.db.Exec("INSERT OR IGNORE INTO quote_votes (message_id, user_id, value) VALUES (?, ?, ?)", messageID, userID, value)
+	} else {
+		_, err = ss.db.Exec("DELETE FROM quote_votes WHERE message_id = ? AND user_id = ? AND value = ?", messageID, userID, value)
+	}
+	return err
+}
+
+func (ss *sqliteStore) Scores(guildID string) (map[int]int, error) {
+	rows, err := ss.db.Query(`SELECT p.quote_number, SUM(v.value)
+		FROM quote_votes v JOIN quote_posts p ON p.message_id = v.message_id
+		WHERE p.guild_id = ? GROUP BY p.quote_number`, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	scores := map[int]int{}
+	for rows.Next() {
+		var id, score int
+		if err := rows.Scan(&id, &score); err != nil {
+			return nil, err
+		}
+		scores[id] = score
+	}
+	return scores, rows.Err()
+}
+
+func (ss *sqliteStore) RecordDaily(guildID string, entry DailyEntry) error {
+	if err := ss.ensureGuild(guildID); err != nil {
+		return err
+	}
+	_, err := ss.db.Exec(`INSERT OR REPLACE INTO daily_history
+		(guild_id, date, channel_id, quote_number, message_id, posted_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		guildID, entry.Date, entry.ChannelID, entry.QuoteID, entry.MessageID, unixSeconds(entry.PostedAt))
+	return err
+}
+
+const dailyColumns = "date, channel_id, quote_number, message_id, posted_at"
+
+func scanDaily(sc scanner) (DailyEntry, error) {
+	var e DailyEntry
+	var postedAt int64
+	err := sc.Scan(&e.Date, &e.ChannelID, &e.QuoteID, &e.MessageID, &postedAt)
+	e.PostedAt = unixTime(postedAt)
+	return e, err
+}
+
+func (ss *sqliteStore) DailyHistory(guildID string, limit int) ([]DailyEntry, error) {
+	rows, err := ss.db.Query("SELECT "+dailyColumns+" FROM daily_history WHERE guild_id = ? ORDER BY date DESC LIMIT ?", guildID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []DailyEntry
+	for rows.Next() {
+		e, err := scanDaily(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (ss *sqliteStore) DailyOn(guildID, date string) (DailyEntry, error) {
+	e, err := scanDaily(ss.db.QueryRow("SELECT "+dailyColumns+" FROM daily_history WHERE guild_id = ? AND date = ?", guildID, date))
+	if errors.Is(err, sql.ErrNoRows) {
+		return DailyEntry{}, errDailyNotFound
+	}
+	return e, err
+}
+
+func (ss *sqliteStore) CommandAccess(guildID string) (map[string]AccessRule, error) {
+	rows, err := ss.db.Query("SELECT command, everyone, roles, permissions FROM command_access WHERE guild_id = ?", guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	access := map[string]AccessRule{}
+	for rows.Next() {
+		var command, roles string
+		var rule AccessRule
+		if err := rows.Scan(&command, &rule.Everyone, &roles, &rule.Permissions); err != nil {
+			return nil, err
+		}
+		rule.Roles = strings.Fields(roles)
+		access[command] = rule
+	}
+	return access, rows.Err()
+}
+
+func (ss *sqliteStore) SetCommandAccess(guildID, command string, rule *AccessRule) error {
+	if err := ss.ensureGuild(guildID); err != nil {
+		return err
+	}
+	if rule == nil {
+		_, err := ss.db.Exec("DELETE FROM command_access WHERE guild_id = ? AND command = ?", guildID, command)
+		return err
+	}
+	_, err := ss.db.Exec("INSERT OR REPLACE INTO command_access (guild_id, command, everyone, roles, permissions) VALUES (?, ?, ?, ?, ?)",
+		guildID, command, rule.Everyone, strings.Join(rule.Roles, " "), rule.Permissions)
+	return err
+}
+
+func (ss *sqliteStore) GemSubscribers(guildID string) ([]string, error) {
+	rows, err := ss.db.Query("SELECT user_id FROM gem_subscribers WHERE guild_id = ? ORDER BY rowid", guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (ss *sqliteStore) AddGemSubscriber(guildID, userID string) (bool, error) {
+	if err := ss.ensureGuild(guildID); err != nil {
+		return false, err
+	}
+	res, err := ss.db.Exec("INSERT OR IGNORE INTO gem_subscribers (guild_id, user_id) VALUES (?, ?)", guildID, userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Czasy trzymamy jako sekundy uniksowe; 0 oznacza "nieznany".
+func unixSeconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func unixTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+func (ss *sqliteStore) Close() error {
+	return ss.db.Close()
+}
+
+// importConfigJSON jednorazowo przenosi stan z config.json do pustej bazy: ID
+// cytatów, kosz, historia zmian, głosy i reszta zostają takie same.
+// Zwraca liczbę zaimportowanych serwerów; 0, gdy baza była już w użyciu.
+func (ss *sqliteStore) importConfigJSON(js *jsonStore) (int, error) {
+	var used int
+	if err := ss.db.QueryRow("SELECT COUNT(*) FROM guilds").Scan(&used); err != nil || used > 0 {
+		return 0, err
+	}
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	imported := 0
+	for guildID, gc := range js.config.Guilds {
+		if guildID == defaultGuildKey {
+			log.Printf("⚠️ UWAGA: stara konfiguracja z %s bez przypisanego serwera nie została zaimportowana. Ustaw DEFAULT_GUILD_ID, usuń bazę i uruchom bota ponownie.", js.path)
+			continue
+		}
+		if err := importGuild(tx, guildID, gc); err != nil {
+			return 0, fmt.Errorf("serwer %s: %w", guildID, err)
+		}
+		imported++
+	}
+	return imported, tx.Commit()
+}
+
+func importGuild(tx *sql.Tx, guildID string, gc *GuildConfig) error {
+	gs := gc.GuildSettings
+	_, err := tx.Exec("INSERT INTO guilds (guild_id, next_quote_id, next_pending_id, "+strings.Join(settingsColumns, ", ")+
+		") VALUES (?, ?, ?"+strings.Repeat(", ?", len(settingsColumns))+")",
+		append([]any{guildID, gc.NextQuoteID, max(gc.NextPendingID, 1)}, settingsFields(&gs)...)...)
+	if err != nil {
+		return err
+	}
+	for _, q := range slices.Concat(gc.Quotes, gc.Trash) {
+		res, err := tx.Exec(`INSERT INTO quotes (guild_id, number, text, author, submitter_id, created_at, source, deleted_at, deleted_by)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`, guildID, q.ID, q.Text, q.Author, q.SubmitterID, unixSeconds(q.CreatedAt), q.Source, unixSeconds(q.DeletedAt), q.DeletedBy)
+		if err != nil {
+			return err
+		}
+		rowID, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		if err := insertTags(tx, rowID, q.Tags); err != nil {
+			return err
+		}
+		for _, rev := range gc.Revisions[q.ID] {
+			_, err := tx.Exec(`INSERT INTO quote_revisions (quote_id, text, author, source, tags, edited_by, edited_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?)`, rowID, rev.Text, rev.Author, rev.Source, strings.Join(rev.Tags, " "), rev.EditedBy, rev.EditedAt.Unix())
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, q := range gc.Pending {
+		_, err := tx.Exec(`INSERT INTO pending_quotes (guild_id, number, text, author, submitter_id, created_at, source, tags)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, guildID, q.ID, q.Text, q.Author, q.SubmitterID, unixSeconds(q.CreatedAt), q.Source, strings.Join(q.Tags, " "))
+		if err != nil {
+			return err
+		}
+	}
+	for i, id := range gc.DailyBag {
+		if _, err := tx.Exec("INSERT INTO daily_bag (guild_id, position, quote_number) VALUES (?, ?, ?)", guildID, i, id); err != nil {
+			return err
+		}
+	}
+	for messageID, post := range gc.Posts {
+		_, err := tx.Exec("INSERT INTO quote_posts (message_id, guild_id, quote_number, posted_at) VALUES (?, ?, ?, ?)",
+			messageID, guildID, post.QuoteID, unixSeconds(post.PostedAt))
+		if err != nil {
+			return err
+		}
+		for value, voters := range map[int][]string{1: post.Up, -1: post.Down} {
+			for _, userID := range voters {
+				if _, err := tx.Exec("INSERT OR IGNORE INTO quote_votes (message_id, user_id, value) VALUES (?, ?, ?)", messageID, userID, value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, e := range gc.History {
+		_, err := tx.Exec(`INSERT OR REPLACE INTO daily_history
+			(guild_id, date, channel_id, quote_number, message_id, posted_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			guildID, e.Date, e.ChannelID, e.QuoteID, e.MessageID, unixSeconds(e.PostedAt))
+		if err != nil {
+			return err
+		}
+	}
+	for command, rule := range gc.Access {
+		_, err := tx.Exec("INSERT INTO command_access (guild_id, command, everyone, roles, permissions) VALUES (?, ?, ?, ?, ?)",
+			guildID, command, rule.Everyone, strings.Join(rule.Roles, " "), rule.Permissions)
+		if err != nil {
+			return err
+		}
+	}
+	for _, userID := range gc.GemSubscribers {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO gem_subscribers (guild_id, user_id) VALUES (?, ?)", guildID, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const testGuild = "100"
+
+var storeBackends = []struct {
+	name string
+	open func(t *testing.T) Store
+}{
+	{"json", func(t *testing.T) Store {
+		js, err := openJSONStore(filepath.Join(t.TempDir(), "config.json"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return js
+	}},
+	{"sqlite", func(t *testing.T) Store {
+		ss, err := openSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { ss.Close() })
+		return ss
+	}},
+}
+
+// forEachStore uruchamia run osobno na świeżym magazynie każdego backendu.
+func forEachStore(t *testing.T, run func(t *testing.T, st Store)) {
+	t.Helper()
+	for _, backend := range storeBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			run(t, backend.open(t))
+		})
+	}
+}
+
+func quoteIDs(quotes []Quote) []int {
+	ids := make([]int, len(quotes))
+	for i, q := range quotes {
+		ids[i] = q.ID
+	}
+	return ids
+}
+
+func mustQuotes(t *testing.T, st Store) []Quote {
+	t.Helper()
+	quotes, err := st.Quotes(testGuild)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return quotes
+}
+
+// TestStoreContract sprawdza, że oba backendy zachowują się tak samo.
+func TestStoreContract(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, st Store)
+	}{
+		{"add", func(t *testing.T, st Store) {
+			q, err := st.AddQuote(testGuild, Quote{Text: "nowa", Author: "ja", Tags: []string{"praca"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if q.ID != 4 {
+				t.Errorf("ID = %d, chcę 4", q.ID)
+			}
+			got, err := st.Quote(testGuild, 4)
+			if err != nil || got.Text != "nowa" || got.Author != "ja" || !slices.Equal(got.Tags, []string{"praca"}) {
+				t.Errorf("Quote(4) = %+v, %v", got, err)
+			}
+			if err := st.DeleteQuote(testGuild, 4, "u1"); err != nil {
+				t.Fatal(err)
+			}
+			if q, _ := st.AddQuote(testGuild, Quote{Text: "kolejna"}); q.ID != 5 {
+				t.Errorf("ID po usunięciu = %d, chcę 5 (ID się nie powtarzają)", q.ID)
+			}
+			if guilds, _ := st.Guilds(); !slices.Equal(guilds, []string{testGuild}) {
+				t.Errorf("Guilds = %v", guilds)
+			}
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			forEachStore(t, tc.run)
+		})
+	}
+}
+
+func TestSQLiteImportsJSON(t *testing.T) {
+	js, err := openJSONStore(filepath.Join(t.TempDir(), "config.json"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	js.AddQuote(testGuild, Quote{Text: "z tagiem", Tags: []string{"praca"}})
+	js.DeleteQuote(testGuild, 2, "u1")
+	js.EditQuote(testGuild, 1, "ed", func(q *Quote) { q.Text = "poprawiona" })
+	js.AddPending(testGuild, Quote{Text: "czeka"})
+	js.RecordPost(testGuild, "m1", 4)
+	js.SetVote(testGuild, "m1", "u1", 1, true)
+	js.UpdateSettings(testGuild, func(gs *GuildSettings) { gs.ChannelID = "123" })
+
+	ss, err := openSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ss.Close()
+	if n, err := ss.importConfigJSON(js); err != nil || n != 1 {
+		t.Fatalf("importConfigJSON = %d, %v", n, err)
+	}
+	quotes := mustQuotes(t, ss)
+	if !slices.Equal(quoteIDs(quotes), []int{1, 3, 4}) || quotes[0].Text != "poprawiona" || !slices.Equal(quotes[2].Tags, []string{"praca"}) {
+		t.Errorf("Quotes = %+v", quotes)
+	}
+	if trash, _ := ss.Trash(testGuild); len(trash) != 1 || trash[0].ID != 2 {
+		t.Errorf("Trash = %+v", trash)
+	}
+	if revs, _ := ss.QuoteRevisions(testGuild, 1); len(revs) != 1 || revs[0].EditedBy != "ed" {
+		t.Errorf("QuoteRevisions = %+v", revs)
+	}
+	if pending, _ := ss.Pending(testGuild); len(pending) != 1 {
+		t.Errorf("Pending = %+v", pending)
+	}
+	if scores, _ := ss.Scores(testGuild); scores[4] != 1 {
+		t.Errorf("Scores = %v", scores)
+	}
+	if settings, _ := ss.Settings(testGuild); settings.ChannelID != "123" {
+		t.Errorf("Settings = %+v", settings)
+	}
+	if q, _ := ss.AddQuote(testGuild, Quote{Text: "po imporcie"}); q.ID != 5 {
+		t.Errorf("ID po imporcie = %d, chcę 5", q.ID)
+	}
+	if p, _ := ss.AddPending(testGuild, Quote{Text: "kolejne"}); p.ID != 2 {
+		t.Errorf("numer zgłoszenia po imporcie = %d, chcę 2", p.ID)
+	}
+	// Baza w użyciu nie importuje drugi raz.
+	if n, err := ss.importConfigJSON(js); err != nil || n != 0 {
+		t.Errorf("drugi importConfigJSON = %d, %v", n, err)
+	}
+}
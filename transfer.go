@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxImportBytes ogranicza rozmiar pliku z !import.
+const maxImportBytes = 1 << 20
+
+var csvHeader = []string{"id", "text", "author", "source", "tags", "submitter_id", "created_at"}
+
+// handleExport obsługuje !eksport [json|csv|md].
+func handleExport(ctx *commandContext, args string) {
+	format := strings.ToLower(strings.TrimSpace(args))
+	if format == "" {
+		format = "json"
+	}
+	quotes, err := store.Quotes(ctx.GuildID)
+	if err != nil {
+		ctx.storeError(ctx.cmd("eksport"), err)
+		return
+	}
+	var data []byte
+	note := "Wczytasz go z powrotem komendą " + ctx.cmd("import") + "."
+	switch format {
+	case "json":
+		data, err = json.MarshalIndent(quotes, "", "  ")
+	case "csv":
+		data, err = exportCSV(quotes)
+	case "md", "markdown":
+		format = "md"
+		data = exportMarkdown(quotes)
+		note = "Markdown jest do czytania - kopię zapasową do " + ctx.cmd("import") + " zrób w json albo csv."
+	default:
+		ctx.reply("❌ Użycie: " + ctx.cmd("eksport") + " [json|csv|md]")
+		return
+	}
+	if err != nil {
+		ctx.storeError(ctx.cmd("eksport"), err)
+		return
+	}
+	_, err = ctx.send(&discordgo.MessageSend{
+		Content: fmt.Sprintf("📤 Eksport %d złotych myśli. %s", len(quotes), note),
+		Files: []*discordgo.File{{
+			Name:   fmt.Sprintf("zlote-mysli-%s.%s", time.Now().Format(dailyDateLayout), format),
+			Reader: bytes.NewReader(data),
+		}},
+	})
+	if err != nil {
+		ctx.storeError(ctx.cmd("eksport"), err)
+	}
+}
+
+func exportCSV(quotes []Quote) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(csvHeader)
+	for _, q := range quotes {
+		created := ""
+		if !q.CreatedAt.IsZero() {
+			created = q.CreatedAt.Format(time.RFC3339)
+		}
+		w.Write([]string{strconv.Itoa(q.ID), q.Text, q.Author, q.Source, strings.Join(q.Tags, " "), q.SubmitterID, created})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// markdownExportMarker oznacza pliki z !eksport md, których !import nie
+// przyjmuje. W podglądzie Markdown komentarza nie widać.
+const markdownExportMarker = "<!-- eksport złotych myśli: tylko do czytania -->"
+
+// exportMarkdown zapisuje każdy cytat jako punkt listy ("#tag tekst | autor |
+// źródło") do czytania. Nie jest kopią zapasową: "|" albo "#słowo" w treści
+// i podziały linii rozjechałyby się przy !import, do tego służą json i csv.
+func exportMarkdown(quotes []Quote) []byte {
+	var b strings.Builder
+	b.WriteString("# Złote myśli\n" + markdownExportMarker + "\n\n")
+	for _, q := range quotes {
+		b.WriteString(fmt.Sprintf("%d. ", q.ID))
+		for _, tag := range q.Tags {
+			b.WriteString("#" + tag + " ")
+		}
+		b.WriteString(strings.Join(strings.Fields(q.Text), " "))
+		if q.Author != "" || q.Source != "" {
+			b.WriteString(" | " + q.Author)
+		}
+		if q.Source != "" {
+			b.WriteString(" | " + q.Source)
+		}
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+// parseImport rozpoznaje format po rozszerzeniu pliku i zwraca cytaty
+// oraz liczbę wpisów, których nie dało się odczytać.
+func parseImport(filename string, data []byte) ([]Quote, int, error) {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".json":
+		return importJSON(data)
+	case ".csv":
+		return importCSV(data)
+	case ".md", ".markdown", ".txt":
+		if bytes.Contains(data, []byte(markdownExportMarker)) {
+			return nil, 0, errors.New("to eksport Markdown do czytania - wczytaj kopię z !eksport json albo csv")
+		}
+		return importMarkdown(data), 0, nil
+	}
+	return nil, 0, errors.New("obsługuję pliki .json, .csv i .md")
+}
+
+// importJSON przyjmuje listę cytatów z !eksport, listę gołych tekstów
+// albo stary config.json z polem "quotes".
+func importJSON(data []byte) ([]Quote, int, error) {
+	var quotes []Quote
+	if err := json.Unmarshal(data, &quotes); err == nil {
+		return quotes, 0, nil
+	}
+	var wrapped struct {
+		Quotes []Quote `json:"quotes"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, 0, fmt.Errorf("nieprawidłowy JSON: %w", err)
+	}
+	return wrapped.Quotes, 0, nil
+}
+
+// importCSV czyta kolumny po nagłówku; bez nagłówka z kolumną "text"
+// pierwsza kolumna jest treścią.
+func importCSV(data []byte) ([]Quote, int, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, 0, fmt.Errorf("nieprawidłowy CSV: %w", err)
+	}
+	col := map[string]int{"text": 0}
+	if len(rows) > 0 {
+		header := map[string]int{}
+		for i, name := range rows[0] {
+			header[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		if _, ok := header["text"]; ok {
+			col, rows = header, rows[1:]
+		}
+	}
+	field := func(row []string, name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+	var quotes []Quote
+	invalid := 0
+	for _, row := range rows {
+		q := Quote{
+			Text:        field(row, "text"),
+			Author:      field(row, "author"),
+			Source:      field(row, "source"),
+			SubmitterID: field(row, "submitter_id"),
+		}
+		q.Tags, _ = parseTags(tagsAsHashes(field(row, "tags")))
+		if created := field(row, "created_at"); created != "" {
+			t, err := time.Parse(time.RFC3339, created)
+			if err != nil {
+				invalid++
+				continue
+			}
+			q.CreatedAt = t
+		}
+		quotes = append(quotes, q)
+	}
+	return quotes, invalid, nil
+}
+
+// tagsAsHashes dopisuje # do tagów z CSV, które go nie mają.
+func tagsAsHashes(tags string) string {
+	fields := strings.Fields(tags)
+	for i, f := range fields {
+		if !strings.HasPrefix(f, "#") {
+			fields[i] = "#" + f
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+var markdownItem = regexp.MustCompile(`^\s*(?:\d+[.)]|[-*+])\s+(.+)$`)
+
+func importMarkdown(data []byte) []Quote {
+	var quotes []Quote
+	for _, line := range strings.Split(string(data), "\n") {
+		if match := markdownItem.FindStringSubmatch(line); match != nil {
+			quotes = append(quotes, parseQuoteArgs(match[1]))
+		}
+	}
+	return quotes
+}
+
+func downloadAttachment(att *discordgo.MessageAttachment) ([]byte, error) {
+	if att.Size > maxImportBytes {
+		return nil, fmt.Errorf("plik jest za duży (limit %d KB)", maxImportBytes/1024)
+	}
+	client := &http.Client{Timeout: 20 * time.Second}
+	resp, err := client.Get(att.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discord status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxImportBytes))
+}
+
+// handleImport obsługuje !import [scal|zastap] z załączonym plikiem.
+// Przy zastąpieniu dotychczasowe cytaty trafiają do kosza, więc da się
+// je jeszcze przywrócić.
+func handleImport(ctx *commandContext, args string) {
+	var replace bool
+	switch foldText(strings.TrimSpace(args)) {
+	case "", "scal":
+	case "zastap":
+		replace = true
+	default:
+		ctx.reply("❌ Użycie: " + ctx.cmd("import") + " [scal|zastap] z dołączonym plikiem .json, .csv albo .md")
+		return
+	}
+	m := ctx.message
+	if len(m.Attachments) != 1 {
+		ctx.reply("❌ Dołącz do " + ctx.cmd("import") + " dokładnie jeden plik .json, .csv albo .md")
+		return
+	}
+	att := m.Attachments[0]
+	data, err := downloadAttachment(att)
+	if err != nil {
+		ctx.reply("❌ Nie udało się pobrać pliku: " + err.Error())
+		return
+	}
+	imported, invalid, err := parseImport(att.Filename, data)
+	if err != nil {
+		ctx.reply("❌ " + withPrefix(err.Error(), ctx.Prefix))
+		return
+	}
+
+	// Najpierw sprawdzamy cały plik; dotychczasowe cytaty ruszamy dopiero
+	// wtedy, gdy jest co wczytać.
+	var existing []Quote
+	if !replace {
+		existing, err = store.Quotes(ctx.GuildID)
+		if err != nil {
+			ctx.storeError(ctx.cmd("import"), err)
+			return
+		}
+	}
+	index := newDuplicateIndex(existing)
+	var accepted []Quote
+	duplicates := 0
+	for _, q := range imported {
+		q.Text = strings.TrimSpace(q.Text)
+		q.Author = strings.TrimSpace(q.Author)
+		q.Source = strings.TrimSpace(q.Source)
+		var tags []string
+		for _, tag := range q.Tags {
+			if tag = normalizeTag(tag); tag != "" && !containsString(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		q.Tags = tags
+		if len(validateQuote(q)) > 0 {
+			invalid++
+			continue
+		}
+		if _, _, dup := index.find(q.Text); dup {
+			duplicates++
+			continue
+		}
+		q.ID = 0
+		q.DeletedAt, q.DeletedBy = time.Time{}, ""
+		if q.SubmitterID == "" {
+			q.SubmitterID = ctx.UserID
+		}
+		if q.CreatedAt.IsZero() {
+			q.CreatedAt = time.Now()
+		}
+		index.add(q)
+		accepted = append(accepted, q)
+	}
+	if len(accepted) == 0 {
+		ctx.reply(fmt.Sprintf("❌ W %s nie ma nic do wczytania (powtórki: %d, puste lub błędne: %d). Niczego nie zmieniono.",
+			att.Filename, duplicates, invalid))
+		return
+	}
+
+	added, trashed, err := store.ImportQuotes(ctx.GuildID, accepted, replace, ctx.UserID)
+	if err != nil {
+		ctx.storeError(ctx.cmd("import"), err)
+		return
+	}
+	ids := make([]int, len(added))
+	for i, q := range added {
+		ids[i] = q.ID
+	}
+	if err := addToDailyBag(ctx.GuildID, ids...); err != nil {
+		log.Println("daily bag error:", err)
+	}
+
+	summary := fmt.Sprintf("📥 Import z %s: dodano %d, pominięto %d (powtórki: %d, puste lub błędne: %d).",
+		att.Filename, len(added), duplicates+invalid, duplicates, invalid)
+	if trashed > 0 {
+		summary += fmt.Sprintf("\n🗑️ %d dotychczasowych złotych myśli przeniesiono do kosza (%s).", trashed, ctx.cmd("kosz"))
+	}
+	ctx.reply(summary)
+}
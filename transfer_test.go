@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	quotes := []Quote{
+		{ID: 1, Text: "Zwykła myśl", Author: "Ktoś", Source: "Książka", Tags: []string{"praca", "zycie"}, SubmitterID: "u1", CreatedAt: created},
+		{ID: 2, Text: "Tekst z \"cudzysłowem\", przecinkiem | kreską\ni #hashem w drugiej linii"},
+	}
+	data, err := exportCSV(quotes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, invalid, err := importCSV(data)
+	if err != nil || invalid != 0 || len(got) != len(quotes) {
+		t.Fatalf("importCSV = %+v, %d, %v", got, invalid, err)
+	}
+	for i, want := range quotes {
+		q := got[i]
+		if q.Text != want.Text || q.Author != want.Author || q.Source != want.Source || q.SubmitterID != want.SubmitterID ||
+			!slices.Equal(q.Tags, want.Tags) || !q.CreatedAt.Equal(want.CreatedAt) {
+			t.Errorf("cytat %d po imporcie = %+v, chcę %+v", i, q, want)
+		}
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		texts   []string
+		invalid int
+	}{
+		{"bez nagłówka", "pierwsza\ndruga,coś\n", []string{"pierwsza", "druga"}, 0},
+		{"kolumny w innej kolejności", "author,text\nja,treść\n", []string{"treść"}, 0},
+		{"zła data", "text,created_at\ndobra,\nzła,wczoraj\n", []string{"dobra"}, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			quotes, invalid, err := importCSV([]byte(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var texts []string
+			for _, q := range quotes {
+				texts = append(texts, q.Text)
+			}
+			if !slices.Equal(texts, tc.texts) || invalid != tc.invalid {
+				t.Errorf("importCSV = %q, %d, chcę %q, %d", texts, invalid, tc.texts, tc.invalid)
+			}
+		})
+	}
+}
+
+func TestImportMarkdown(t *testing.T) {
+	data := "# Moje myśli\n\nWstęp, który nie jest cytatem.\n" +
+		"1. #praca Pierwsza | Autor | Źródło\n" +
+		"- Druga\n" +
+		"  * Trzecia | Ktoś\n"
+	want := []Quote{
+		{Text: "Pierwsza", Author: "Autor", Source: "Źródło", Tags: []string{"praca"}},
+		{Text: "Druga"},
+		{Text: "Trzecia", Author: "Ktoś"},
+	}
+	got := importMarkdown([]byte(data))
+	if len(got) != len(want) {
+		t.Fatalf("importMarkdown = %+v", got)
+	}
+	for i := range want {
+		if got[i].Text != want[i].Text || got[i].Author != want[i].Author || got[i].Source != want[i].Source || !slices.Equal(got[i].Tags, want[i].Tags) {
+			t.Errorf("cytat %d = %+v, chcę %+v", i, got[i], want[i])
+		}
+	}
+}
+
+// TestParseImportRejectsMarkdownExport pilnuje, żeby eksport do czytania nie
+// wrócił przez !import z rozjechanymi polami.
+func TestParseImportRejectsMarkdownExport(t *testing.T) {
+	data := exportMarkdown([]Quote{{ID: 1, Text: "a | b", Tags: []string{"x"}}})
+	if _, _, err := parseImport("zlote-mysli.md", data); err == nil {
+		t.Error("parseImport przyjął eksport Markdown")
+	}
+}
+
+func TestImportJSON(t *testing.T) {
+	list, _ := json.Marshal([]Quote{{ID: 7, Text: "z eksportu"}})
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{"eksport", string(list), []string{"z eksportu"}},
+		{"gołe teksty", `["pierwsza", "druga"]`, []string{"pierwsza", "druga"}},
+		{"stary config", `{"quotes": ["stara"], "channel_id": "1"}`, []string{"stara"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			quotes, _, err := importJSON([]byte(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var texts []string
+			for _, q := range quotes {
+				texts = append(texts, q.Text)
+			}
+			if !slices.Equal(texts, tc.want) {
+				t.Errorf("importJSON = %q, chcę %q", texts, tc.want)
+			}
+		})
+	}
+	if _, _, err := importJSON([]byte("nie json")); err == nil {
+		t.Error("importJSON przyjął zepsuty plik")
+	}
+}
+
+func TestImportQuotes(t *testing.T) {
+	forEachStore(t, func(t *testing.T, st Store) {
+		added, trashed, err := st.ImportQuotes(testGuild, []Quote{{Text: "czwarta"}}, false, "u1")
+		if err != nil || trashed != 0 || !slices.Equal(quoteIDs(added), []int{4}) {
+			t.Fatalf("ImportQuotes scal = %v, %d, %v", quoteIDs(added), trashed, err)
+		}
+		added, trashed, err = st.ImportQuotes(testGuild, []Quote{{Text: "piąta", Tags: []string{"nowe"}}, {Text: "szósta"}}, true, "u1")
+		if err != nil || trashed != 4 || !slices.Equal(quoteIDs(added), []int{5, 6}) {
+			t.Fatalf("ImportQuotes zastąp = %v, %d, %v", quoteIDs(added), trashed, err)
+		}
+		quotes := mustQuotes(t, st)
+		if !slices.Equal(quoteIDs(quotes), []int{5, 6}) || !slices.Equal(quotes[0].Tags, []string{"nowe"}) {
+			t.Errorf("Quotes = %+v", quotes)
+		}
+		if trash, _ := st.Trash(testGuild); len(trash) != 4 || trash[0].DeletedBy != "u1" {
+			t.Errorf("Trash = %+v", trash)
+		}
+	})
+}
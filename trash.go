@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+const defaultTrashRetentionDays = 30
+
+// trashRetention mówi, jak długo usunięte cytaty czekają w koszu
+// (zmienna TRASH_RETENTION_DAYS, domyślnie 30 dni).
+func trashRetention() time.Duration {
+	days := defaultTrashRetentionDays
+	if v := os.Getenv("TRASH_RETENTION_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			days = n
+		} else {
+			log.Printf("Nieprawidłowe TRASH_RETENTION_DAYS=%q, używam %d dni", v, days)
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func purgeTrash() {
+	n, err := store.PurgeTrash(time.Now().Add(-trashRetention()))
+	if err != nil {
+		log.Println("purge trash error:", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("Kosz: trwale usunięto %d cytatów", n)
+	}
+}
+
+func sendTrashList(ctx *commandContext) {
+	trash, err := store.Trash(ctx.GuildID)
+	if err != nil {
+		ctx.storeError(ctx.cmd("kosz"), err)
+		return
+	}
+	if len(trash) == 0 {
+		ctx.reply("🗑️ Kosz jest pusty.")
+		return
+	}
+	retention := trashRetention()
+	lines := make([]string, len(trash))
+	for i, q := range trash {
+		left := int(time.Until(q.DeletedAt.Add(retention)).Hours()/24) + 1
+		line := fmt.Sprintf("%d. %s (usunięte %s", q.ID, truncateRunes(q.Text, 80), q.DeletedAt.Format("2006-01-02"))
+		if q.DeletedBy != "" {
+			line += fmt.Sprintf(" przez <@%s>", q.DeletedBy)
+		}
+		lines[i] = line + fmt.Sprintf(", zniknie za %d dni)", max(left, 0))
+	}
+	sendPages(ctx, "Kosz - przywróć komendą "+ctx.cmd("przywroc")+" <numer>", lines)
+}
+
+func handleRestore(ctx *commandContext, args string) {
+	id, ok := parseQuoteID(args)
+	if !ok {
+		ctx.reply("❌ Podaj numer z kosza, np. " + ctx.cmd("przywroc") + " 12")
+		return
+	}
+	quote, err := store.RestoreQuote(ctx.GuildID, id)
+	if errors.Is(err, errQuoteNotFound) {
+		ctx.reply(fmt.Sprintf("❌ W koszu nie ma złotej myśli #%d!", id))
+		return
+	}
+	if err != nil {
+		ctx.storeError(ctx.cmd("przywroc"), err)
+		return
+	}
+	if err := addToDailyBag(ctx.GuildID, quote.ID); err != nil {
+		log.Println("daily bag error:", err)
+	}
+	ctx.reply(formatQuote(fmt.Sprintf("♻️ Przywrócono złotą myśl #%d:", id), quote))
+}
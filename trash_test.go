@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestTrashRestore sprawdza przeniesienie do kosza i przywrócenie pod tym samym ID.
+func TestTrashRestore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, st Store) {
+		if err := st.DeleteQuote(testGuild, 2, "u1"); err != nil {
+			t.Fatal(err)
+		}
+		if got := quoteIDs(mustQuotes(t, st)); !slices.Equal(got, []int{1, 3}) {
+			t.Errorf("Quotes = %v", got)
+		}
+		if _, err := st.Quote(testGuild, 2); !errors.Is(err, errQuoteNotFound) {
+			t.Errorf("Quote(2) = %v, chcę errQuoteNotFound", err)
+		}
+		if err := st.DeleteQuote(testGuild, 2, "u1"); !errors.Is(err, errQuoteNotFound) {
+			t.Errorf("drugie DeleteQuote = %v", err)
+		}
+		trash, err := st.Trash(testGuild)
+		if err != nil || len(trash) != 1 || trash[0].ID != 2 || trash[0].DeletedBy != "u1" || trash[0].DeletedAt.IsZero() {
+			t.Fatalf("Trash = %+v, %v", trash, err)
+		}
+		restored, err := st.RestoreQuote(testGuild, 2)
+		if err != nil || restored.ID != 2 || !restored.DeletedAt.IsZero() {
+			t.Fatalf("RestoreQuote = %+v, %v", restored, err)
+		}
+		if got := quoteIDs(mustQuotes(t, st)); !slices.Equal(got, []int{1, 2, 3}) {
+			t.Errorf("Quotes po przywróceniu = %v", got)
+		}
+		if _, err := st.RestoreQuote(testGuild, 2); !errors.Is(err, errQuoteNotFound) {
+			t.Errorf("drugie RestoreQuote = %v", err)
+		}
+		if trash, _ := st.Trash(testGuild); len(trash) != 0 {
+			t.Errorf("Trash = %+v, chcę pustego", trash)
+		}
+	})
+}
+
+func TestPurgeTrash(t *testing.T) {
+	forEachStore(t, func(t *testing.T, st Store) {
+		st.DeleteQuote(testGuild, 2, "u1")
+		if n, err := st.PurgeTrash(time.Now().Add(-time.Hour)); err != nil || n != 0 {
+			t.Errorf("PurgeTrash przed usunięciem = %d, %v", n, err)
+		}
+		if n, err := st.PurgeTrash(time.Now().Add(2 * time.Second)); err != nil || n != 1 {
+			t.Errorf("PurgeTrash = %d, %v, chcę 1", n, err)
+		}
+		if trash, _ := st.Trash(testGuild); len(trash) != 0 {
+			t.Errorf("Trash = %+v, chcę pustego", trash)
+		}
+		if _, err := st.RestoreQuote(testGuild, 2); !errors.Is(err, errQuoteNotFound) {
+			t.Errorf("RestoreQuote po opróżnieniu = %v", err)
+		}
+		if q, _ := st.AddQuote(testGuild, Quote{Text: "nowa"}); q.ID != 4 {
+			t.Errorf("ID po opróżnieniu kosza = %d, chcę 4", q.ID)
+		}
+	})
+}
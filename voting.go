@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	voteUp   = "👍"
+	voteDown = "👎"
+)
+
+// trackQuotePost zapamiętuje wysłany cytat i dodaje pod nim 👍/👎, żeby
+// głosowanie było jednym kliknięciem.
+func trackQuotePost(s *discordgo.Session, guildID string, msg *discordgo.Message, quoteID int) {
+	if msg == nil {
+		return
+	}
+	if err := store.RecordPost(guildID, msg.ID, quoteID); err != nil {
+		log.Println("record post error:", err)
+		return
+	}
+	for _, emoji := range []string{voteUp, voteDown} {
+		if err := s.MessageReactionAdd(msg.ChannelID, msg.ID, emoji); err != nil {
+			log.Println("vote reaction error:", err)
+		}
+	}
+}
+
+// voteValue zamienia emoji reakcji na głos; odcienie skóry liczą się tak samo.
+func voteValue(emoji string) int {
+	switch {
+	case strings.HasPrefix(emoji, voteUp):
+		return 1
+	case strings.HasPrefix(emoji, voteDown):
+		return -1
+	}
+	return 0
+}
+
+func messageReactionAdd(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	handleVote(s, r.MessageReaction, true)
+}
+
+func messageReactionRemove(s *discordgo.Session, r *discordgo.MessageReactionRemove) {
+	handleVote(s, r.MessageReaction, false)
+}
+
+func handleVote(s *discordgo.Session, r *discordgo.MessageReaction, on bool) {
+	if r.GuildID == "" || r.UserID == s.State.User.ID {
+		return
+	}
+	value := voteValue(r.Emoji.Name)
+	if value == 0 {
+		return
+	}
+	err := store.SetVote(r.GuildID, r.MessageID, r.UserID, value, on)
+	if err != nil && !errors.Is(err, errPostNotFound) {
+		log.Println("vote error:", err)
+	}
+}
+
+// quoteWeight przelicza wynik na wagę losowania: każdy głos na plus
+// dokłada jedną "kopię" cytatu, głosy na minus ją proporcjonalnie zmniejszają.
+func quoteWeight(score int) float64 {
+	if score >= 0 {
+		return float64(1 + score)
+	}
+	return 1 / float64(1-score)
+}
+
+func pickWeighted(quotes []Quote, scores map[int]int) Quote {
+	total := 0.0
+	for _, q := range quotes {
+		total += quoteWeight(scores[q.ID])
+	}
+	x := rand.Float64() * total
+	for _, q := range quotes {
+		x -= quoteWeight(scores[q.ID])
+		if x < 0 {
+			return q
+		}
+	}
+	return quotes[len(quotes)-1]
+}
+
+// sendRanking wysyła !top (best=true) albo !flop - cytaty z głosami,
+// posortowane po wyniku.
+func sendRanking(ctx *commandContext, best bool) {
+	command := ctx.cmd("flop")
+	if best {
+		command = ctx.cmd("top")
+	}
+	quotes, err := store.Quotes(ctx.GuildID)
+	var scores map[int]int
+	if err == nil {
+		scores, err = store.Scores(ctx.GuildID)
+	}
+	if err != nil {
+		ctx.storeError(command, err)
+		return
+	}
+	var ranked []Quote
+	for _, q := range quotes {
+		if _, ok := scores[q.ID]; ok {
+			ranked = append(ranked, q)
+		}
+	}
+	if len(ranked) == 0 {
+		ctx.reply("Nikt jeszcze nie głosował. Reaguj 👍/👎 pod złotymi myślami!")
+		return
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if best {
+			return scores[ranked[i].ID] > scores[ranked[j].ID]
+		}
+		return scores[ranked[i].ID] < scores[ranked[j].ID]
+	})
+
+	const limit = 10
+	title := "🏆 Najlepsze złote myśli"
+	if !best {
+		title = "🥀 Najsłabsze złote myśli"
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("**%s:**\n\n", title))
+	for i, q := range ranked[:min(limit, len(ranked))] {
+		b.WriteString(fmt.Sprintf("%d. (%+d) #%d %s\n", i+1, scores[q.ID], q.ID, truncateRunes(q.Text, 100)))
+	}
+	ctx.reply(b.String())
+}
+
+func handleWeighting(ctx *commandContext, args string) {
+	var weighted bool
+	switch strings.ToLower(args) {
+	case "on", "wl", "wł", "tak":
+		weighted = true
+	case "off", "wyl", "wył", "nie":
+		weighted = false
+	default:
+		ctx.reply("❌ Użycie: " + ctx.cmd("wagi") + " on|off")
+		return
+	}
+	err := store.UpdateSettings(ctx.GuildID, func(gs *GuildSettings) {
+		gs.WeightedRandom = weighted
+	})
+	if err != nil {
+		ctx.storeError(ctx.cmd("wagi"), err)
+		return
+	}
+	if weighted {
+		ctx.reply("✅ " + ctx.cmd("zm") + " będzie częściej losować złote myśli z lepszym wynikiem.")
+	} else {
+		ctx.reply("✅ " + ctx.cmd("zm") + " losuje wszystkie złote myśli z równym prawdopodobieństwem.")
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestVotes sprawdza, że powtórny głos się nie sumuje, a zdjęty znika z wyniku.
+func TestVotes(t *testing.T) {
+	forEachStore(t, func(t *testing.T, st Store) {
+		if err := st.SetVote(testGuild, "m0", "u1", 1, true); !errors.Is(err, errPostNotFound) {
+			t.Errorf("SetVote na obcej wiadomości = %v", err)
+		}
+		st.RecordPost(testGuild, "m1", 1)
+		st.RecordPost(testGuild, "m2", 1)
+		st.RecordPost(testGuild, "m3", 2)
+		st.RecordPost(testGuild, "m4", 3)
+		for _, v := range []struct {
+			msg, user string
+			value     int
+		}{{"m1", "u1", 1}, {"m1", "u1", 1}, {"m2", "u2", 1}, {"m3", "u1", -1}} {
+			if err := st.SetVote(testGuild, v.msg, v.user, v.value, true); err != nil {
+				t.Fatal(err)
+			}
+		}
+		st.SetVote(testGuild, "m2", "u2", 1, false)
+		scores, err := st.Scores(testGuild)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(scores) != 2 || scores[1] != 1 || scores[2] != -1 {
+			t.Errorf("Scores = %v, chcę map[1:1 2:-1]", scores)
+		}
+	})
+}